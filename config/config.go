@@ -26,6 +26,7 @@ type Config struct {
 	DBIP                         string
 	DBPort                       int64
 	DBName                       string
+	DBSlowQueryThresholdMs       int64
 	NotificationBotToken         string
 	NotificationChannelID        string
 	CurationFeedChannelID        string
@@ -35,6 +36,7 @@ type Config struct {
 	ArchiveIndexerServerURL      string
 	FlashfreezeIngestDirFullPath string
 	FixesDirFullPath             string
+	MaxFilesPerSubmission        int64 // 0 means unlimited
 }
 
 func EnvString(name string) string {
@@ -100,6 +102,7 @@ func GetConfig(l *logrus.Entry) *Config {
 		DBIP:                         EnvString("DB_IP"),
 		DBPort:                       EnvInt("DB_PORT"),
 		DBName:                       EnvString("DB_NAME"),
+		DBSlowQueryThresholdMs:       EnvInt("DB_SLOW_QUERY_THRESHOLD_MILLISECONDS"),
 		NotificationBotToken:         EnvString("NOTIFICATION_BOT_TOKEN"),
 		NotificationChannelID:        EnvString("NOTIFICATION_CHANNEL_ID"),
 		CurationFeedChannelID:        EnvString("CURATION_FEED_CHANNEL_ID"),
@@ -109,5 +112,6 @@ func GetConfig(l *logrus.Entry) *Config {
 		ArchiveIndexerServerURL:      EnvString("ARCHIVE_INDEXER_SERVER_URL"),
 		FlashfreezeIngestDirFullPath: EnvString("FLASHFREEZE_INGEST_DIR_FULL_PATH"),
 		FixesDirFullPath:             EnvString("FIXES_DIR_FULL_PATH"),
+		MaxFilesPerSubmission:        EnvInt("MAX_FILES_PER_SUBMISSION"),
 	}
 }
@@ -2,9 +2,11 @@ package constants
 
 const ValidatorID = 810112564787675166
 const SystemID = 844246603102945333
+const AnonymizedUserID = 1 // takes over everything authored by users who asked to be forgotten
 const SubmissionsDir = "files/submissions"
 const SubmissionImagesDir = "files/submissions-images"
 const UserInAuditSubmissionMaxFilesize = 500000000
+const NotificationMaxAttempts = 5 // failed deliveries after which a notification is given up on
 
 const (
 	ActionComment              = "comment"
@@ -21,6 +23,7 @@ const (
 	ActionReject               = "reject"
 	ActionAuditionUpload       = "audition-upload"
 	ActionAuditionSubscribe    = "audition-subscribe"
+	ActionCommentSubscribe     = "comment-subscribe"
 )
 
 const (
@@ -29,6 +32,148 @@ const (
 	SubmissionLevelStaff    = "staff"
 )
 
+const SubmissionMetaKeyMergedInto = "merged-into" // submission meta pointing a merged submission to the one it was merged into
+
+const (
+	SubmissionStatusNew          = "new"
+	SubmissionStatusInReview     = "in-review"
+	SubmissionStatusNeedsChanges = "needs-changes"
+	SubmissionStatusApproved     = "approved"
+	SubmissionStatusRejected     = "rejected"
+	SubmissionStatusAccepted     = "accepted"
+)
+
+func GetAllowedSubmissionStatuses() []string {
+	return []string{
+		SubmissionStatusNew,
+		SubmissionStatusInReview,
+		SubmissionStatusNeedsChanges,
+		SubmissionStatusApproved,
+		SubmissionStatusRejected,
+		SubmissionStatusAccepted,
+	}
+}
+
+const (
+	TimeBucketDay   = "day"
+	TimeBucketWeek  = "week"
+	TimeBucketMonth = "month"
+)
+
+func GetAllowedTimeBuckets() []string {
+	return []string{
+		TimeBucketDay,
+		TimeBucketWeek,
+		TimeBucketMonth,
+	}
+}
+
+// GetAllowedStatusTransitions returns the statuses a submission may move to from each status.
+// Decisions can be revisited and files can be uploaded at any time, only requesting changes on an accepted submission is not allowed.
+func GetAllowedStatusTransitions() map[string][]string {
+	return map[string][]string{
+		SubmissionStatusNew:          {SubmissionStatusInReview, SubmissionStatusNeedsChanges, SubmissionStatusApproved, SubmissionStatusRejected, SubmissionStatusAccepted},
+		SubmissionStatusInReview:     {SubmissionStatusNew, SubmissionStatusNeedsChanges, SubmissionStatusApproved, SubmissionStatusRejected, SubmissionStatusAccepted},
+		SubmissionStatusNeedsChanges: {SubmissionStatusNew, SubmissionStatusInReview, SubmissionStatusApproved, SubmissionStatusRejected, SubmissionStatusAccepted},
+		SubmissionStatusApproved:     {SubmissionStatusNew, SubmissionStatusInReview, SubmissionStatusNeedsChanges, SubmissionStatusRejected, SubmissionStatusAccepted},
+		SubmissionStatusRejected:     {SubmissionStatusNew, SubmissionStatusInReview, SubmissionStatusNeedsChanges, SubmissionStatusApproved, SubmissionStatusAccepted},
+		SubmissionStatusAccepted:     {SubmissionStatusNew, SubmissionStatusInReview, SubmissionStatusApproved, SubmissionStatusRejected},
+	}
+}
+
+// IsStatusTransitionAllowed checks a status change against GetAllowedStatusTransitions
+func IsStatusTransitionAllowed(from, to string) bool {
+	for _, allowed := range GetAllowedStatusTransitions()[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// SubmissionStatusForAction returns the review status a submission moves to after a given action, if the action changes it
+func SubmissionStatusForAction(action string) (string, bool) {
+	switch action {
+	case ActionUpload:
+		return SubmissionStatusNew, true
+	case ActionAssignTesting, ActionAssignVerification:
+		return SubmissionStatusInReview, true
+	case ActionApprove, ActionVerify:
+		return SubmissionStatusApproved, true
+	case ActionRequestChanges:
+		return SubmissionStatusNeedsChanges, true
+	case ActionReject:
+		return SubmissionStatusRejected, true
+	case ActionMarkAdded:
+		return SubmissionStatusAccepted, true
+	}
+	return "", false
+}
+
+// GetStatusChangingActions returns the actions for which SubmissionStatusForAction returns a status
+func GetStatusChangingActions() []string {
+	return []string{
+		ActionUpload,
+		ActionAssignTesting,
+		ActionAssignVerification,
+		ActionApprove,
+		ActionVerify,
+		ActionRequestChanges,
+		ActionReject,
+		ActionMarkAdded,
+	}
+}
+
+const (
+	CurationImageTypeLogo       = "logo"
+	CurationImageTypeScreenshot = "screenshot"
+)
+
+func GetAllowedCurationImageTypes() []string {
+	return []string{
+		CurationImageTypeLogo,
+		CurationImageTypeScreenshot,
+	}
+}
+
+const (
+	CommentReactionAgree    = "agree"
+	CommentReactionDisagree = "disagree"
+	CommentReactionThanks   = "thanks"
+)
+
+func GetAllowedCommentReactions() []string {
+	return []string{
+		CommentReactionAgree,
+		CommentReactionDisagree,
+		CommentReactionThanks,
+	}
+}
+
+const (
+	CommentFormatPlain    = "plain"
+	CommentFormatMarkdown = "markdown"
+)
+
+func GetAllowedCommentFormats() []string {
+	return []string{
+		CommentFormatPlain,
+		CommentFormatMarkdown,
+	}
+}
+
+const (
+	CommentVisibilityPublic   = "public"
+	CommentVisibilityInternal = "internal" // staff only
+)
+
+func GetAllowedCommentVisibilities() []string {
+	return []string{
+		CommentVisibilityPublic,
+		CommentVisibilityInternal,
+	}
+}
+
 func GetAllowedActions() []string {
 	return []string{
 		ActionComment,
@@ -61,6 +206,7 @@ func GetActionsWithNotification() []string {
 		ActionMarkAdded,
 		ActionUpload,
 		ActionReject,
+		ActionCommentSubscribe,
 	}
 }
 
@@ -70,6 +216,7 @@ const (
 	ResourceKeyFileID                = "file-id"
 	ResourceKeyFileIDs               = "file-ids"
 	ResourceKeyCommentID             = "comment-id"
+	ResourceKeyCommentReaction       = "comment-reaction"
 	ResourceKeyCurationImageID       = "curation-image-id"
 	ResourceKeyFlashfreezeRootFileID = "flashfreeze-root-file-id"
 	ResourceKeyFixID                 = "fix-id"
@@ -0,0 +1,30 @@
+package constants
+
+import "testing"
+
+func TestIsStatusTransitionAllowed(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want bool
+	}{
+		{"new to in review", SubmissionStatusNew, SubmissionStatusInReview, true},
+		{"in review to needs changes", SubmissionStatusInReview, SubmissionStatusNeedsChanges, true},
+		{"needs changes to new", SubmissionStatusNeedsChanges, SubmissionStatusNew, true},
+		{"approved to accepted", SubmissionStatusApproved, SubmissionStatusAccepted, true},
+		{"rejected to approved", SubmissionStatusRejected, SubmissionStatusApproved, true},
+		{"accepted to new", SubmissionStatusAccepted, SubmissionStatusNew, true},
+		{"accepted to needs changes", SubmissionStatusAccepted, SubmissionStatusNeedsChanges, false},
+		{"unchanged status", SubmissionStatusNew, SubmissionStatusNew, false},
+		{"unknown from", "foo", SubmissionStatusNew, false},
+		{"unknown to", SubmissionStatusNew, "foo", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsStatusTransitionAllowed(tt.from, tt.to); got != tt.want {
+				t.Errorf("IsStatusTransitionAllowed(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
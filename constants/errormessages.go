@@ -1,6 +1,19 @@
 package constants
 
 const (
-	ErrorCannotDeleteLastSubmissionFile = "cannot delete last submission file for a given submission"
-	ErrorFailedToBeginTransaction       = "failed to begin transaction"
+	ErrorCannotDeleteLastSubmissionFile       = "cannot delete last submission file for a given submission"
+	ErrorFailedToBeginTransaction             = "failed to begin transaction"
+	ErrorInvalidCurationImageType             = "invalid curation image type"
+	ErrorQuotaExceeded                        = "storage quota exceeded"
+	ErrorSubmissionFileLimitReached           = "submission file limit reached"
+	ErrorParentCommentFromDifferentSubmission = "parent comment belongs to a different submission"
+	ErrorInvalidCommentReaction               = "invalid comment reaction"
+	ErrorInvalidCurationMetaField             = "invalid curation meta field"
+	ErrorInvalidSubmissionStatus              = "invalid submission status"
+	ErrorDisplayNameTooLong                   = "display name is too long"
+	ErrorSubmissionAlreadyAccepted            = "submission is already accepted"
+	ErrorCannotAnonymizeUser                  = "cannot anonymize this user"
+	ErrorCannotMergeSubmissionIntoItself      = "cannot merge a submission into itself"
+	ErrorInvalidStatusTransition              = "invalid submission status transition"
+	ErrorInvalidTimeBucket                    = "invalid time bucket"
 )
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"github.com/Dri0m/flashpoint-submission-system/constants"
+	"github.com/Dri0m/flashpoint-submission-system/types"
+	"github.com/Dri0m/flashpoint-submission-system/utils"
+	"strings"
+	"time"
+)
+
+// ArchiveAcceptedSubmissions moves submissions accepted before olderThan out of the live tables and returns how many were moved.
+// The submission, its files, curation meta, images, comments, submission meta, status history and file downloads
+// are copied into the _archive tables as they are. Everything else hanging off the submission is either derived from those
+// or only matters while it is reviewed, so it is deleted.
+// Acceptance is the export into the main database if recorded, the last mark-added action otherwise.
+// Everything happens in the session's transaction, so a submission is either moved completely or not at all.
+func (d *mysqlDAL) ArchiveAcceptedSubmissions(dbs DBSession, olderThan time.Time) (int64, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT submission.id FROM submission
+		WHERE submission.status = ?
+		AND COALESCE(submission.accepted_at, (SELECT MAX(comment.created_at) FROM comment
+		                                      JOIN action ON action.id = comment.fk_action_id
+		                                      WHERE comment.fk_submission_id = submission.id AND comment.deleted_at IS NULL
+		                                      AND action.name = ?)) < ?
+		ORDER BY submission.id
+		FOR UPDATE`,
+		constants.SubmissionStatusAccepted, constants.ActionMarkAdded, olderThan.Unix())
+	if err != nil {
+		return 0, err
+	}
+	sids := make([]int64, 0)
+	for rows.Next() {
+		var sid int64
+		if err := rows.Scan(&sid); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		sids = append(sids, sid)
+	}
+	rows.Close()
+
+	const submissionFiles = `(SELECT id FROM submission_file WHERE fk_submission_id = ?)`
+	// ordered so that nothing is deleted while something else still refers to it
+	statements := []string{
+		`INSERT INTO submission_archive SELECT * FROM submission WHERE id = ?`,
+		`INSERT INTO submission_file_archive SELECT * FROM submission_file WHERE fk_submission_id = ?`,
+		`INSERT INTO curation_meta_archive SELECT * FROM curation_meta WHERE fk_submission_file_id IN ` + submissionFiles,
+		`INSERT INTO curation_image_archive SELECT * FROM curation_image WHERE fk_submission_file_id IN ` + submissionFiles,
+		`INSERT INTO comment_archive SELECT * FROM comment WHERE fk_submission_id = ?`,
+		`INSERT INTO submission_meta_archive SELECT * FROM submission_meta WHERE fk_submission_id = ?`,
+		`INSERT INTO status_transition_archive SELECT * FROM status_transition WHERE fk_submission_id = ?`,
+		`INSERT INTO file_download_archive SELECT * FROM file_download WHERE fk_submission_file_id IN ` + submissionFiles,
+		`DELETE FROM submission_cache WHERE fk_submission_id = ?`,
+		`DELETE FROM curation_tag WHERE fk_submission_file_id IN ` + submissionFiles,
+		`DELETE FROM curation_language WHERE fk_submission_file_id IN ` + submissionFiles,
+		`DELETE FROM file_download WHERE fk_submission_file_id IN ` + submissionFiles,
+		`DELETE FROM curation_image WHERE fk_submission_file_id IN ` + submissionFiles,
+		`DELETE FROM curation_meta WHERE fk_submission_file_id IN ` + submissionFiles,
+		`DELETE FROM submission_file WHERE fk_submission_id = ?`,
+		`DELETE FROM comment_reaction WHERE fk_comment_id IN (SELECT id FROM comment WHERE fk_submission_id = ?)`,
+		`UPDATE comment SET fk_parent_comment_id = NULL WHERE fk_submission_id = ?`,
+		`DELETE FROM comment WHERE fk_submission_id = ?`,
+		`DELETE FROM submission_meta WHERE fk_submission_id = ?`,
+		`DELETE FROM submission_view WHERE fk_submission_id = ?`,
+		`DELETE FROM submission_notification_subscription WHERE fk_submission_id = ?`,
+		`DELETE FROM reviewer_note WHERE fk_submission_id = ?`,
+		`DELETE FROM status_transition WHERE fk_submission_id = ?`,
+		`DELETE FROM submission WHERE id = ?`,
+	}
+
+	for _, sid := range sids {
+		for _, statement := range statements {
+			if _, err := dbs.Tx().ExecContext(dbs.Ctx(), statement, sid); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return int64(len(sids)), nil
+}
+
+// SearchArchivedSubmissions returns up to limit archived submissions, newest first, whose title contains the query
+// or whose main database game ID equals it. Titles come from the newest file of a submission,
+// the submitter is the owner if ownership was handed over, the uploader of the oldest file otherwise.
+// Limit is capped to a sane maximum.
+func (d *mysqlDAL) SearchArchivedSubmissions(dbs DBSession, query string, limit int) ([]*types.ArchivedSubmission, error) {
+	const maxLimit = 100
+	if limit < 1 || limit > maxLimit {
+		limit = maxLimit
+	}
+
+	query = strings.TrimSpace(query)
+	if len(query) == 0 {
+		return []*types.ArchivedSubmission{}, nil
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT submission_archive.id, COALESCE(submission_archive.fk_owner_id, oldest_file.fk_user_id), COALESCE(discord_user.display_name, discord_user.username),
+		       meta.title, meta.platform, submission_archive.accepted_game_id, submission_archive.accepted_at
+		FROM submission_archive
+		LEFT JOIN submission_file_archive AS newest_file ON newest_file.id = (
+			SELECT id FROM submission_file_archive
+			WHERE fk_submission_id = submission_archive.id AND deleted_at IS NULL
+			ORDER BY created_at DESC
+			LIMIT 1)
+		LEFT JOIN submission_file_archive AS oldest_file ON oldest_file.id = (
+			SELECT id FROM submission_file_archive
+			WHERE fk_submission_id = submission_archive.id AND deleted_at IS NULL
+			ORDER BY created_at
+			LIMIT 1)
+		LEFT JOIN curation_meta_archive AS meta ON meta.fk_submission_file_id = newest_file.id
+		LEFT JOIN discord_user ON discord_user.id = COALESCE(submission_archive.fk_owner_id, oldest_file.fk_user_id)
+		WHERE meta.title LIKE ? OR submission_archive.accepted_game_id = ?
+		ORDER BY submission_archive.id DESC
+		LIMIT ?`,
+		utils.FormatLike(utils.EscapeLike(query)), query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*types.ArchivedSubmission, 0)
+	for rows.Next() {
+		as := &types.ArchivedSubmission{}
+		var acceptedAt *int64
+		if err := rows.Scan(&as.ID, &as.SubmitterID, &as.SubmitterUsername, &as.Title, &as.Platform, &as.AcceptedGameID, &acceptedAt); err != nil {
+			return nil, err
+		}
+		if acceptedAt != nil {
+			t := time.Unix(*acceptedAt, 0)
+			as.AcceptedAt = &t
+		}
+		result = append(result, as)
+	}
+
+	return result, nil
+}
@@ -4,11 +4,119 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/Dri0m/flashpoint-submission-system/constants"
+	"github.com/Dri0m/flashpoint-submission-system/types"
 	"github.com/Dri0m/flashpoint-submission-system/utils"
 	"strings"
 	"time"
 )
 
+// RecomputeSubmissionDerivedFields recomputes everything derived from the source rows of a single submission:
+// the review status, the normalized curation meta fields and the submission cache.
+// It only reads the current state of the submission, so it is idempotent and safe to call after any change.
+func (d *mysqlDAL) RecomputeSubmissionDerivedFields(dbs DBSession, sid int64) error {
+	// the status follows the latest state-changing human action
+	var actorUID int64 = constants.SystemID
+	status := constants.SubmissionStatusNew
+	_, latestAction, authorID, err := getStatusDefiningComment(dbs, sid)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil {
+		if s, ok := constants.SubmissionStatusForAction(latestAction); ok {
+			status = s
+			actorUID = authorID
+		}
+	}
+
+	// an export into the main database is final, whatever the comments say
+	var accepted bool
+	if err := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT accepted_at IS NOT NULL FROM submission WHERE id = ?`, sid).Scan(&accepted); err != nil {
+		return err
+	}
+	if accepted {
+		status = constants.SubmissionStatusAccepted
+		actorUID = constants.SystemID
+	}
+	// recorded without the legality check, the comments it follows from are already stored
+	if err := d.setSubmissionStatus(dbs, sid, status, actorUID, false); err != nil {
+		return err
+	}
+
+	type rawMeta struct {
+		id            int64
+		source        *string
+		launchCommand *string
+	}
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT meta.id, meta.source, meta.launch_command FROM curation_meta meta
+		JOIN submission_file ON submission_file.id = meta.fk_submission_file_id
+		WHERE submission_file.fk_submission_id = ?`,
+		sid)
+	if err != nil {
+		return err
+	}
+	metas := make([]rawMeta, 0)
+	for rows.Next() {
+		var m rawMeta
+		if err := rows.Scan(&m.id, &m.source, &m.launchCommand); err != nil {
+			rows.Close()
+			return err
+		}
+		metas = append(metas, m)
+	}
+	rows.Close()
+
+	for _, m := range metas {
+		normalizedSource, normalizedLaunchCommand := normalizeCurationMetaFields(m.source, m.launchCommand)
+		_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
+			UPDATE curation_meta SET normalized_source = ?, normalized_launch_command = ?
+			WHERE id = ?`,
+			normalizedSource, normalizedLaunchCommand, m.id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return d.UpdateSubmissionCacheTable(dbs, sid)
+}
+
+// getStatusDefiningComment returns ID, action and author of the latest state-changing human action comment of a submission.
+// The validator bot is not a reviewer, so its comments never define the status.
+func getStatusDefiningComment(dbs DBSession, sid int64) (int64, string, int64, error) {
+	actions := constants.GetStatusChangingActions()
+	args := []interface{}{sid, constants.ValidatorID}
+	for _, action := range actions {
+		args = append(args, action)
+	}
+
+	var cid, authorID int64
+	var action string
+	err := dbs.Tx().QueryRowContext(dbs.Ctx(), `
+		SELECT comment.id, action.name, comment.fk_user_id FROM comment
+		JOIN action ON action.id = comment.fk_action_id
+		WHERE comment.fk_submission_id = ? AND comment.deleted_at IS NULL AND comment.fk_user_id != ?
+		AND action.name IN (?`+strings.Repeat(",?", len(actions)-1)+`)
+		ORDER BY comment.created_at DESC, comment.id DESC
+		LIMIT 1`,
+		args...).Scan(&cid, &action, &authorID)
+	if err != nil {
+		return 0, "", 0, err
+	}
+
+	return cid, action, authorID, nil
+}
+
+// GetStatusDefiningComment returns the comment which set the current review status of a submission, with author data.
+// Returns sql.ErrNoRows if no reviewer acted on the submission yet.
+func (d *mysqlDAL) GetStatusDefiningComment(dbs DBSession, sid int64) (*types.ExtendedComment, error) {
+	cid, _, _, err := getStatusDefiningComment(dbs, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.GetExtendedCommentByID(dbs, cid)
+}
+
 func (d *mysqlDAL) UpdateSubmissionCacheTable(dbs DBSession, sid int64) error {
 	l := utils.LogCtx(dbs.Ctx()).WithField("event", "cache-table-update").WithField("table", "submission_cache")
 	l.Debug("updating submission cache table")
@@ -0,0 +1,189 @@
+package database
+
+import (
+	"database/sql"
+	"github.com/Dri0m/flashpoint-submission-system/types"
+)
+
+// ExportSubmission gathers a submission with all of its files, curation metas, images, comments and metadata
+func (d *mysqlDAL) ExportSubmission(dbs DBSession, sid int64) (*types.SubmissionExport, error) {
+	submissions, _, err := d.SearchSubmissions(dbs, &types.SubmissionsFilter{SubmissionIDs: []int64{sid}, ExcludeLegacy: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(submissions) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	files, err := d.GetExtendedSubmissionFilesBySubmissionID(dbs, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	metas := make([]*types.CurationMeta, 0, len(files))
+	images := make([]*types.CurationImage, 0)
+	for _, file := range files {
+		meta, err := d.GetCurationMetaBySubmissionFileID(dbs, file.FileID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		if meta != nil {
+			metas = append(metas, meta)
+		}
+
+		ci, err := d.GetCurationImagesBySubmissionFileID(dbs, file.FileID)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, ci...)
+	}
+
+	comments, err := d.GetExtendedCommentsBySubmissionID(dbs, sid, "")
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := d.GetSubmissionMeta(dbs, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.SubmissionExport{
+		Submission:     submissions[0],
+		Files:          files,
+		CurationMetas:  metas,
+		CurationImages: images,
+		Comments:       comments,
+		Meta:           meta,
+	}, nil
+}
+
+// ImportSubmission recreates an exported submission with its files, curation metas, images, comments and metadata,
+// remapping user IDs using uidMap. Users which are neither remapped nor known to this instance are replaced by fallbackUID.
+// Reply threads and pins are restored, pins are attributed to fallbackUID as the export does not say who pinned a comment.
+// The review status is derived from the imported comments. Returns ID of the new submission.
+func (d *mysqlDAL) ImportSubmission(dbs DBSession, export *types.SubmissionExport, uidMap map[int64]int64, fallbackUID int64) (int64, error) {
+	knownUsers := make(map[int64]bool)
+
+	resolveUID := func(uid int64) (int64, error) {
+		if mapped, ok := uidMap[uid]; ok {
+			return mapped, nil
+		}
+		known, ok := knownUsers[uid]
+		if !ok {
+			_, err := d.GetDiscordUser(dbs, uid)
+			if err != nil && err != sql.ErrNoRows {
+				return 0, err
+			}
+			known = err == nil
+			knownUsers[uid] = known
+		}
+		if !known {
+			return fallbackUID, nil
+		}
+		return uid, nil
+	}
+
+	sid, err := d.StoreSubmission(dbs, export.Submission.SubmissionLevel, false)
+	if err != nil {
+		return 0, err
+	}
+
+	fileIDs := make(map[int64]int64, len(export.Files))
+	for _, f := range export.Files {
+		uid, err := resolveUID(f.SubmitterID)
+		if err != nil {
+			return 0, err
+		}
+		sfid, err := d.StoreSubmissionFile(dbs, &types.SubmissionFile{
+			SubmitterID:      uid,
+			SubmissionID:     sid,
+			OriginalFilename: f.OriginalFilename,
+			CurrentFilename:  f.CurrentFilename,
+			Size:             f.Size,
+			UploadedAt:       f.UploadedAt,
+			MD5Sum:           f.MD5Sum,
+			SHA256Sum:        f.SHA256Sum,
+		})
+		if err != nil {
+			return 0, err
+		}
+		fileIDs[f.FileID] = sfid
+	}
+
+	for _, cm := range export.CurationMetas {
+		sfid, ok := fileIDs[cm.SubmissionFileID]
+		if !ok {
+			continue
+		}
+		meta := *cm
+		meta.SubmissionID = sid
+		meta.SubmissionFileID = sfid
+		if err := d.StoreCurationMeta(dbs, &meta); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, ci := range export.CurationImages {
+		sfid, ok := fileIDs[ci.SubmissionFileID]
+		if !ok {
+			continue
+		}
+		if _, err := d.StoreCurationImage(dbs, &types.CurationImage{SubmissionFileID: sfid, Type: ci.Type, Filename: ci.Filename}); err != nil {
+			return 0, err
+		}
+	}
+
+	commentIDs := make(map[int64]int64, len(export.Comments))
+	for _, c := range export.Comments {
+		uid, err := resolveUID(c.AuthorID)
+		if err != nil {
+			return 0, err
+		}
+		cid, err := d.storeComment(dbs, &types.Comment{
+			AuthorID:     uid,
+			SubmissionID: sid,
+			Action:       c.Action,
+			Message:      c.Message,
+			Format:       c.Format,
+			Visibility:   c.Visibility,
+			CreatedAt:    c.CreatedAt,
+		})
+		if err != nil {
+			return 0, err
+		}
+		commentIDs[c.CommentID] = cid
+		if c.Pinned {
+			if err := d.PinComment(dbs, cid, fallbackUID); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	// parents are linked once every comment exists, so the order of the exported comments does not matter
+	for _, c := range export.Comments {
+		if c.ParentCommentID == nil {
+			continue
+		}
+		parentCID, ok := commentIDs[*c.ParentCommentID]
+		if !ok {
+			continue
+		}
+		_, err := dbs.Tx().ExecContext(dbs.Ctx(), `UPDATE comment SET fk_parent_comment_id = ? WHERE id = ?`, parentCID, commentIDs[c.CommentID])
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for key, value := range export.Meta {
+		if err := d.SetSubmissionMeta(dbs, sid, key, value); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := d.RecomputeSubmissionDerivedFields(dbs, sid); err != nil {
+		return 0, err
+	}
+
+	return sid, nil
+}
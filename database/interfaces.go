@@ -4,37 +4,119 @@ import (
 	"context"
 	"database/sql"
 	"github.com/Dri0m/flashpoint-submission-system/types"
+	"github.com/sirupsen/logrus"
+	"sync"
 	"time"
 )
 
 type DAL interface {
 	NewSession(ctx context.Context) (DBSession, error)
+	Close() error
+	Stats() sql.DBStats
+	LogStats(l *logrus.Entry, ctx context.Context, wg *sync.WaitGroup)
 	StoreSession(dbs DBSession, key string, uid int64, durationSeconds int64) error
 	DeleteSession(dbs DBSession, secret string) error
+	DeleteSessionReturningUID(dbs DBSession, secret string) (int64, error)
 	GetUIDFromSession(dbs DBSession, key string) (int64, bool, error)
+	ValidateAndExtendSession(dbs DBSession, secret string, extendBy int64) (int64, bool, error)
 
 	StoreDiscordUser(dbs DBSession, discordUser *types.DiscordUser) error
 	GetDiscordUser(dbs DBSession, uid int64) (*types.DiscordUser, error)
+	GetDiscordUserNoCache(dbs DBSession, uid int64) (*types.DiscordUser, error)
+	SetDisplayName(dbs DBSession, uid int64, name string) error
+	AnonymizeUser(dbs DBSession, uid int64) (*types.UserAnonymization, error)
+	DeauthorizeUserFully(dbs DBSession, uid int64, removeWatches bool) (*types.UserDeauthorization, error)
+	GetInactiveAuthorizedUsers(dbs DBSession, since time.Time) ([]*types.DiscordUser, error)
 	StoreDiscordServerRoles(dbs DBSession, roles []types.DiscordRole) error
 	StoreDiscordUserRoles(dbs DBSession, uid int64, roles []int64) error
 	GetDiscordUserRoles(dbs DBSession, uid int64) ([]string, error)
-
-	StoreSubmission(dbs DBSession, submissionLevel string) (int64, error)
+	GetDiscordUsersRoles(dbs DBSession, uids []int64) (map[int64][]string, error)
+	SyncDiscordRoleMembers(dbs DBSession, rid int64, uids []int64, grantOnly bool) ([]int64, error)
+
+	StoreSubmission(dbs DBSession, submissionLevel string, isDraft bool) (int64, error)
+	TransferSubmissionOwnership(dbs DBSession, sid, newOwnerUID int64) error
+	PublishSubmission(dbs DBSession, sid int64) error
+	SetReviewDeadline(dbs DBSession, sid, deadline int64) error
+	SetSubmissionPriority(dbs DBSession, sid, priority int64) error
+	SetSubmissionStatus(dbs DBSession, sid int64, status string, actorUID int64) error
+	RecordStatusTransition(dbs DBSession, sid int64, from, to string, actorUID int64) error
+	GetStatusTransitions(dbs DBSession, sid int64) ([]*types.StatusTransition, error)
+	MarkSubmissionAccepted(dbs DBSession, sid int64, gameID string) error
+	ArchiveAcceptedSubmissions(dbs DBSession, olderThan time.Time) (int64, error)
+	SearchArchivedSubmissions(dbs DBSession, query string, limit int) ([]*types.ArchivedSubmission, error)
+	IncrementSubmissionViews(dbs DBSession, sid, uid int64) error
+	GetSubmissionViews(dbs DBSession, sid int64) ([]*types.DailyViewCount, error)
+	SetSubmissionMeta(dbs DBSession, sid int64, key, value string) error
+	GetSubmissionMeta(dbs DBSession, sid int64) (map[string]string, error)
+	DeleteSubmissionMeta(dbs DBSession, sid int64, key string) error
 	StoreSubmissionFile(dbs DBSession, s *types.SubmissionFile) (int64, error)
+	StoreSubmissionFileWithQuota(dbs DBSession, s *types.SubmissionFile, quotaBytes int64) (int64, error)
+	StoreSubmissionFileWithLimit(dbs DBSession, s *types.SubmissionFile, maxFiles int) (int64, error)
+	CountSubmissionFiles(dbs DBSession, sid int64) (int, error)
 	GetSubmissionFiles(dbs DBSession, sfids []int64) ([]*types.SubmissionFile, error)
+	RecordFileDownload(dbs DBSession, sfid, uid int64, ip string) error
+	GetFileDownloadHistory(dbs DBSession, sfid int64) ([]*types.FileDownload, error)
+	GetDownloadCount(dbs DBSession, sfid int64) (int64, error)
 	GetExtendedSubmissionFilesBySubmissionID(dbs DBSession, sid int64) ([]*types.ExtendedSubmissionFile, error)
+	GetOrphanedSubmissionFiles(dbs DBSession) ([]*types.SubmissionFile, error)
+	GetSubmissionsWithNoFiles(dbs DBSession) ([]int64, error)
 
 	SearchSubmissions(dbs DBSession, filter *types.SubmissionsFilter) ([]*types.ExtendedSubmission, int64, error)
+	SearchSubmissionsByTag(dbs DBSession, tag string, filter *types.SubmissionsFilter) ([]*types.ExtendedSubmission, int64, error)
+	GetSubmissionsByDeauthorizedUsers(dbs DBSession, filter *types.SubmissionsFilter) ([]*types.ExtendedSubmission, int64, error)
+	GetSubmissionsReadyForAcceptance(dbs DBSession) ([]*types.ExtendedSubmission, error)
+	GetRandomSubmissions(dbs DBSession, count int, filter *types.SubmissionsFilter) ([]*types.ExtendedSubmission, error)
+	GetSubmissionsGroupedBySubmitter(dbs DBSession, uids []int64) (map[int64][]*types.ExtendedSubmission, error)
+	GetSubmissionsWithDisallowedFiles(dbs DBSession, allowedExtensions []string) ([]*types.ExtendedSubmission, error)
+	GetOldestUnreviewedSubmissions(dbs DBSession, limit int) ([]*types.ExtendedSubmission, error)
+	FindSubmissionsByNormalizedTitle(dbs DBSession, title string) ([]*types.ExtendedSubmission, error)
+	GetExtendedSubmissionsByIDs(dbs DBSession, sids []int64) ([]*types.ExtendedSubmission, error)
+	GetSubmissionDetail(dbs DBSession, sid int64, visibility string) (*types.SubmissionDetail, error)
+	FindExistingTitles(dbs DBSession, titles []string) (map[string]bool, error)
+	GetRecentlyActiveSubmissions(dbs DBSession, limit int) ([]*types.ExtendedSubmission, error)
+	GetSubmissionsWithBotAction(dbs DBSession, action string) ([]*types.ExtendedSubmission, error)
+	GetSubmissionsBySource(dbs DBSession, sourceURL string) ([]*types.ExtendedSubmission, error)
+	FindSubmissionsByLaunchCommand(dbs DBSession, launchCommand string) ([]*types.ExtendedSubmission, error)
+	GetSubmissionsWithIncompleteMeta(dbs DBSession, requiredFields []string) ([]*types.ExtendedSubmission, error)
+	GetSubmissionsWithMetaConflicts(dbs DBSession) ([]*types.SubmissionMetaConflict, error)
 
 	StoreCurationMeta(dbs DBSession, cm *types.CurationMeta) error
 	GetCurationMetaBySubmissionFileID(dbs DBSession, sfid int64) (*types.CurationMeta, error)
+	GetCurationMetasByFileIDs(dbs DBSession, sfids []int64) (map[int64]*types.CurationMeta, error)
+	GetSubmissionsByLanguage(dbs DBSession, code string, filter *types.SubmissionsFilter) ([]*types.ExtendedSubmission, int64, error)
+	GetLanguageDistribution(dbs DBSession) (map[string]int, error)
+	GetDistinctPlatforms(dbs DBSession) ([]string, error)
+	GetDistinctLibraries(dbs DBSession) ([]string, error)
+	GetDistinctCurationStatuses(dbs DBSession) ([]string, error)
+	DiffCurationMeta(dbs DBSession, oldSFID, newSFID int64) (*types.CurationMetaDiff, error)
+	ValidateTagCategories(dbs DBSession, meta *types.CurationMeta) ([]string, error)
 
 	StoreComment(dbs DBSession, c *types.Comment) error
-	GetExtendedCommentsBySubmissionID(dbs DBSession, sid int64) ([]*types.ExtendedComment, error)
+	CommentAndSetStatus(dbs DBSession, c *types.Comment, status string) (int64, error)
+	AssignSubmissions(dbs DBSession, sids []int64, assigneeUID, actorUID int64) (*types.BulkAssignmentResult, error)
+	GetExtendedCommentsBySubmissionID(dbs DBSession, sid int64, visibility string) ([]*types.ExtendedComment, error)
+	GetExtendedCommentsBySubmissionIDFiltered(dbs DBSession, sid int64, actions []string, visibility string) ([]*types.ExtendedComment, error)
 	GetCommentByID(dbs DBSession, cid int64) (*types.Comment, error)
+	GetExtendedCommentByID(dbs DBSession, cid int64) (*types.ExtendedComment, error)
+	SearchComments(dbs DBSession, query string, limit int) ([]*types.ExtendedComment, error)
+	GetRecentComments(dbs DBSession, limit, offset int) ([]*types.ExtendedComment, error)
+	SaveReviewerNote(dbs DBSession, sid, uid int64, body string) error
+	GetReviewerNotes(dbs DBSession, sid int64, authorID *int64) ([]*types.ReviewerNote, error)
+	GetCommentsForDigest(dbs DBSession, since time.Time) (map[int64][]*types.ExtendedComment, error)
+	GetCommentRateByUser(dbs DBSession, uid int64, window time.Duration) (int, error)
+	GetUsersExceedingCommentRate(dbs DBSession, window time.Duration, threshold int) ([]*types.CommentRate, error)
+	GetCommentTreeBySubmissionID(dbs DBSession, sid int64) ([]*types.CommentTreeNode, error)
+	GetSubmissionActionTimeline(dbs DBSession, sid int64) ([]*types.ActionEvent, error)
+	PinComment(dbs DBSession, cid, actorUID int64) error
+	UnpinComment(dbs DBSession, cid int64) error
+	AddCommentReaction(dbs DBSession, cid, uid int64, reaction string) error
+	RemoveCommentReaction(dbs DBSession, cid, uid int64, reaction string) error
+	PopulateCommentReactions(dbs DBSession, comments []*types.ExtendedComment, viewerUID int64) error
 
 	SoftDeleteSubmissionFile(dbs DBSession, sfid int64, deleteReason string) error
+	DeleteSubmissionFile(dbs DBSession, sfid int64, force bool) (int64, error)
 	SoftDeleteSubmission(dbs DBSession, sid int64, deleteReason string) error
+	MergeSubmissions(dbs DBSession, keepSID, mergeSID int64) (*types.SubmissionMerge, error)
 	SoftDeleteComment(dbs DBSession, cid int64, deleteReason string) error
 
 	StoreNotificationSettings(dbs DBSession, uid int64, actions []string) error
@@ -43,12 +125,16 @@ type DAL interface {
 	SubscribeUserToSubmission(dbs DBSession, uid, sid int64) error
 	UnsubscribeUserFromSubmission(dbs DBSession, uid, sid int64) error
 	IsUserSubscribedToSubmission(dbs DBSession, uid, sid int64) (bool, error)
+	GetSubmissionSubscribers(dbs DBSession, sid int64) ([]int64, error)
+	GetSubscribedSubmissions(dbs DBSession, uid int64) ([]*types.ExtendedSubmission, error)
 
 	StoreNotification(dbs DBSession, msg, notificationType string) error
 	GetUsersForNotification(dbs DBSession, authorID, sid int64, action string) ([]int64, error)
 	GetUsersForUniversalNotification(dbs DBSession, authorID int64, action string) ([]int64, error)
 	GetOldestUnsentNotification(dbs DBSession) (*types.Notification, error)
 	MarkNotificationAsSent(dbs DBSession, nid int64) error
+	GetDueNotifications(dbs DBSession, limit int) ([]*types.Notification, error)
+	MarkNotificationFailed(dbs DBSession, nid int64, retryAfter time.Duration) error
 
 	StoreCurationImage(dbs DBSession, c *types.CurationImage) (int64, error)
 	GetCurationImagesBySubmissionFileID(dbs DBSession, sfid int64) ([]*types.CurationImage, error)
@@ -58,6 +144,11 @@ type DAL interface {
 	GetPreviousSubmission(dbs DBSession, sid int64) (int64, error)
 
 	UpdateSubmissionCacheTable(dbs DBSession, sid int64) error
+	RecomputeSubmissionDerivedFields(dbs DBSession, sid int64) error
+	GetStatusDefiningComment(dbs DBSession, sid int64) (*types.ExtendedComment, error)
+
+	ExportSubmission(dbs DBSession, sid int64) (*types.SubmissionExport, error)
+	ImportSubmission(dbs DBSession, export *types.SubmissionExport, uidMap map[int64]int64, fallbackUID int64) (int64, error)
 
 	ClearMasterDBGames(dbs DBSession) error
 	StoreMasterDBGames(dbs DBSession, games []*types.MasterDatabaseGame) error
@@ -80,6 +171,10 @@ type DAL interface {
 	GetFixesFiles(dbs DBSession, ffids []int64) ([]*types.FixesFile, error)
 
 	DeleteUserSessions(dbs DBSession, uid int64) (int64, error)
+	DeleteSessionsOlderThan(dbs DBSession, cutoff time.Time) (int64, error)
+	DeleteAllSessions(dbs DBSession) (int64, error)
+	GetActiveSessionSummary(dbs DBSession, limit int) (*types.SessionSummary, error)
+	MergeUser(dbs DBSession, fromUID, toUID int64, tombstone bool) (map[string]int64, error)
 
 	GetTotalCommentsCount(dbs DBSession) (int64, error)
 	GetTotalUserCount(dbs DBSession) (int64, error)
@@ -87,11 +182,19 @@ type DAL interface {
 	GetTotalFlashfreezeFileCount(dbs DBSession) (int64, error)
 	GetTotalSubmissionFilesize(dbs DBSession) (int64, error)
 	GetTotalFlashfreezeFilesize(dbs DBSession) (int64, error)
+	GetTotalStorageUsed(dbs DBSession, excludeDeleted bool) (int64, error)
+	GetStorageUsedByUser(dbs DBSession, uid int64, excludeDeleted bool) (int64, error)
+	GetActionUsageStats(dbs DBSession, since, until *time.Time) (map[string]*types.ActionUsage, error)
+	GetTopSubmitters(dbs DBSession, since, until time.Time, limit int) ([]*types.SubmitterCount, error)
+	GenerateQueueReport(dbs DBSession) (*types.QueueReport, error)
+	GetProcessingLatencyStats(dbs DBSession, since, until time.Time) (*types.LatencyStats, error)
+	GetSubmitterGrowth(dbs DBSession, bucket string, since, until time.Time) ([]*types.TimeBucketCount, error)
+	CountSubmissionsAwaitingUser(dbs DBSession, uid int64) (int, error)
 }
 
 type DBSession interface {
 	Commit() error
 	Rollback() error
-	Tx() *sql.Tx
+	Tx() *Tx
 	Ctx() context.Context
 }
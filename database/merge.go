@@ -0,0 +1,128 @@
+package database
+
+import (
+	"fmt"
+)
+
+// MergeUser moves submissions, files, comments, ownership, subscriptions, reactions, reviewer notes, views, downloads
+// and status history of one user to another and returns affected row counts per table.
+// Assignments are comment actions, so they move together with the comments. Where both users have the same reaction, subscription
+// or view, the target user's one is kept. Reviewer notes on the same submission are joined into the target user's note.
+// If tombstone is set, the old user is renamed, loses sessions, roles and notification settings, but the row is kept for history.
+func (d *mysqlDAL) MergeUser(dbs DBSession, fromUID, toUID int64, tombstone bool) (map[string]int64, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT fk_submission_id FROM comment WHERE fk_user_id = ?
+		UNION
+		SELECT fk_submission_id FROM submission_file WHERE fk_user_id = ?`,
+		fromUID, fromUID)
+	if err != nil {
+		return nil, err
+	}
+	sids := make([]int64, 0)
+	for rows.Next() {
+		var sid int64
+		if err := rows.Scan(&sid); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		sids = append(sids, sid)
+	}
+	rows.Close()
+
+	result := make(map[string]int64)
+
+	type mergeUpdate struct {
+		name  string
+		query string
+		args  []interface{}
+	}
+
+	updates := []mergeUpdate{
+		{"submission_file", `UPDATE submission_file SET fk_user_id = ? WHERE fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		{"comment", `UPDATE comment SET fk_user_id = ? WHERE fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		{"comment_pinned_by", `UPDATE comment SET fk_pinned_by_id = ? WHERE fk_pinned_by_id = ?`, []interface{}{toUID, fromUID}},
+		{"submission", `UPDATE submission SET fk_owner_id = ? WHERE fk_owner_id = ?`, []interface{}{toUID, fromUID}},
+		{"submission_file_archive", `UPDATE submission_file_archive SET fk_user_id = ? WHERE fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		{"comment_archive", `UPDATE comment_archive SET fk_user_id = ? WHERE fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		{"comment_archive_pinned_by", `UPDATE comment_archive SET fk_pinned_by_id = ? WHERE fk_pinned_by_id = ?`, []interface{}{toUID, fromUID}},
+		{"submission_archive", `UPDATE submission_archive SET fk_owner_id = ? WHERE fk_owner_id = ?`, []interface{}{toUID, fromUID}},
+		{"file_download_archive", `UPDATE file_download_archive SET fk_user_id = ? WHERE fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		{"status_transition_archive", `UPDATE status_transition_archive SET fk_actor_id = ? WHERE fk_actor_id = ?`, []interface{}{toUID, fromUID}},
+		// drop subscriptions the target user already has, move the rest
+		{"submission_notification_subscription_duplicates", `
+			DELETE old_sns FROM submission_notification_subscription AS old_sns
+			JOIN submission_notification_subscription AS new_sns ON new_sns.fk_submission_id = old_sns.fk_submission_id AND new_sns.fk_user_id = ?
+			WHERE old_sns.fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		{"submission_notification_subscription", `UPDATE submission_notification_subscription SET fk_user_id = ? WHERE fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		// drop reactions the target user already has, move the rest
+		{"comment_reaction_duplicates", `
+			DELETE old_cr FROM comment_reaction AS old_cr
+			JOIN comment_reaction AS new_cr ON new_cr.fk_comment_id = old_cr.fk_comment_id AND new_cr.reaction = old_cr.reaction AND new_cr.fk_user_id = ?
+			WHERE old_cr.fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		{"comment_reaction", `UPDATE comment_reaction SET fk_user_id = ? WHERE fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		// join notes on submissions the target user already has a note on, move the rest
+		{"reviewer_note_joined", `
+			UPDATE reviewer_note AS new_note
+			JOIN reviewer_note AS old_note ON old_note.fk_submission_id = new_note.fk_submission_id AND old_note.fk_user_id = ?
+			SET new_note.body = CONCAT(new_note.body, '\n\n', old_note.body), new_note.updated_at = GREATEST(new_note.updated_at, old_note.updated_at)
+			WHERE new_note.fk_user_id = ?`, []interface{}{fromUID, toUID}},
+		{"reviewer_note_duplicates", `
+			DELETE old_note FROM reviewer_note AS old_note
+			JOIN reviewer_note AS new_note ON new_note.fk_submission_id = old_note.fk_submission_id AND new_note.fk_user_id = ?
+			WHERE old_note.fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		{"reviewer_note", `UPDATE reviewer_note SET fk_user_id = ? WHERE fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		// view_count counts the view rows, so it drops together with the views both users had on the same day
+		{"submission_view_count", `
+			UPDATE submission
+			JOIN (SELECT old_sv.fk_submission_id, COUNT(*) AS duplicates FROM submission_view AS old_sv
+			      JOIN submission_view AS new_sv ON new_sv.fk_submission_id = old_sv.fk_submission_id AND new_sv.view_date = old_sv.view_date AND new_sv.fk_user_id = ?
+			      WHERE old_sv.fk_user_id = ?
+			      GROUP BY old_sv.fk_submission_id) AS dup ON dup.fk_submission_id = submission.id
+			SET submission.view_count = submission.view_count - dup.duplicates`, []interface{}{toUID, fromUID}},
+		{"submission_view_duplicates", `
+			DELETE old_sv FROM submission_view AS old_sv
+			JOIN submission_view AS new_sv ON new_sv.fk_submission_id = old_sv.fk_submission_id AND new_sv.view_date = old_sv.view_date AND new_sv.fk_user_id = ?
+			WHERE old_sv.fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		{"submission_view", `UPDATE submission_view SET fk_user_id = ? WHERE fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		{"file_download", `UPDATE file_download SET fk_user_id = ? WHERE fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		{"status_transition", `UPDATE status_transition SET fk_actor_id = ? WHERE fk_actor_id = ?`, []interface{}{toUID, fromUID}},
+		{"flashfreeze_file", `UPDATE flashfreeze_file SET fk_user_id = ? WHERE fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		{"fixes", `UPDATE fixes SET fk_user_id = ? WHERE fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		{"fixes_deleted_by", `UPDATE fixes SET fk_deleted_by_user_id = ? WHERE fk_deleted_by_user_id = ?`, []interface{}{toUID, fromUID}},
+		{"fixes_file", `UPDATE fixes_file SET fk_user_id = ? WHERE fk_user_id = ?`, []interface{}{toUID, fromUID}},
+		{"fixes_file_deleted_by", `UPDATE fixes_file SET fk_deleted_by_user_id = ? WHERE fk_deleted_by_user_id = ?`, []interface{}{toUID, fromUID}},
+	}
+
+	if tombstone {
+		updates = append(updates, []mergeUpdate{
+			{"session", `DELETE FROM session WHERE uid = ?`, []interface{}{fromUID}},
+			{"discord_user_role", `DELETE FROM discord_user_role WHERE fk_uid = ?`, []interface{}{fromUID}},
+			{"notification_settings", `DELETE FROM notification_settings WHERE fk_user_id = ?`, []interface{}{fromUID}},
+			{"discord_user", `UPDATE discord_user SET username = ?, avatar = '' WHERE id = ?`, []interface{}{fmt.Sprintf("merged-into-%d", toUID), fromUID}},
+		}...)
+	}
+
+	for _, u := range updates {
+		res, err := dbs.Tx().ExecContext(dbs.Ctx(), u.query, u.args...)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		result[u.name] = affected
+	}
+
+	if tombstone {
+		d.discordUsers.invalidate(fromUID)
+	}
+
+	for _, sid := range sids {
+		if err := d.UpdateSubmissionCacheTable(dbs, sid); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
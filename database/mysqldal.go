@@ -11,31 +11,67 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/golang-migrate/migrate/source/file"
 	"github.com/sirupsen/logrus"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
+const maxDisplayNameLength = 127
+
 type mysqlDAL struct {
-	db *sql.DB
+	db                 *sql.DB
+	statements         *statementCache
+	lockRetryCount     int
+	lockRetryBaseDelay time.Duration
+	discordUsers       *discordUserCache
+	slowQueries        *slowQueryLogger
 }
 
-func NewMysqlDAL(conn *sql.DB) *mysqlDAL {
+// NewMysqlDAL creates DAL over given connection pool, statements running longer than slowQueryThreshold are logged
+func NewMysqlDAL(l *logrus.Entry, conn *sql.DB, slowQueryThreshold time.Duration) *mysqlDAL {
 	return &mysqlDAL{
-		db: conn,
+		db:                 conn,
+		slowQueries:        &slowQueryLogger{l: l, threshold: slowQueryThreshold},
+		statements:         newStatementCache(),
+		lockRetryCount:     defaultLockRetryCount,
+		lockRetryBaseDelay: defaultLockRetryBaseDelay,
+		discordUsers:       newDiscordUserCache(defaultDiscordUserCacheSize, defaultDiscordUserCacheTTL),
 	}
 }
 
+// DBOptions describe where to connect and how to set up the connections
+type DBOptions struct {
+	User     string
+	Password string
+	IP       string
+	Port     int64
+	Name     string
+	// LockWaitTimeoutSeconds sets innodb_lock_wait_timeout of every connection, 0 keeps the server default
+	LockWaitTimeoutSeconds int64
+}
+
 // OpenDB opens DAL or panics
 func OpenDB(l *logrus.Entry, conf *config.Config) *sql.DB {
+	return OpenDBWithOptions(l, DBOptions{
+		User:     conf.DBUser,
+		Password: conf.DBPassword,
+		IP:       conf.DBIP,
+		Port:     conf.DBPort,
+		Name:     conf.DBName,
+	})
+}
 
-	user := conf.DBUser
-	pass := conf.DBPassword
-	ip := conf.DBIP
-	port := conf.DBPort
-	dbName := conf.DBName
+// OpenDBWithOptions opens DAL with given options or panics
+func OpenDBWithOptions(l *logrus.Entry, opts DBOptions) *sql.DB {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?multiStatements=true", opts.User, opts.Password, opts.IP, opts.Port, opts.Name)
+	if opts.LockWaitTimeoutSeconds > 0 {
+		dsn += fmt.Sprintf("&innodb_lock_wait_timeout=%d", opts.LockWaitTimeoutSeconds)
+	}
 
-	db, err := sql.Open("mysql",
-		fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?multiStatements=true", user, pass, ip, port, dbName))
+	db, err := sql.Open("mysql", dsn)
 	if err != nil {
 		l.Fatal(err)
 	}
@@ -45,7 +81,7 @@ func OpenDB(l *logrus.Entry, conf *config.Config) *sql.DB {
 
 type MysqlSession struct {
 	context     context.Context
-	transaction *sql.Tx
+	transaction *Tx
 }
 
 // NewSession begins a transaction
@@ -57,7 +93,7 @@ func (d *mysqlDAL) NewSession(ctx context.Context) (DBSession, error) {
 
 	return &MysqlSession{
 		context:     ctx,
-		transaction: tx,
+		transaction: &Tx{Tx: tx, slowQueries: d.slowQueries},
 	}, nil
 }
 
@@ -76,7 +112,7 @@ func (dbs *MysqlSession) Rollback() error {
 	return err
 }
 
-func (dbs *MysqlSession) Tx() *sql.Tx {
+func (dbs *MysqlSession) Tx() *Tx {
 	return dbs.transaction
 }
 
@@ -86,8 +122,9 @@ func (dbs *MysqlSession) Ctx() context.Context {
 
 // StoreSession store session into the DAL with set expiration date
 func (d *mysqlDAL) StoreSession(dbs DBSession, key string, uid int64, durationSeconds int64) error {
-	expiration := time.Now().Add(time.Second * time.Duration(durationSeconds)).Unix()
-	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `INSERT INTO session (secret, uid, expires_at) VALUES (?, ?, ?)`, key, uid, expiration)
+	now := time.Now()
+	expiration := now.Add(time.Second * time.Duration(durationSeconds)).Unix()
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `INSERT INTO session (secret, uid, expires_at, created_at) VALUES (?, ?, ?, ?)`, key, uid, expiration, now.Unix())
 	return err
 }
 
@@ -97,14 +134,32 @@ func (d *mysqlDAL) DeleteSession(dbs DBSession, secret string) error {
 	return err
 }
 
+// DeleteSessionReturningUID deletes specific session and returns ID of the user it belonged to, sql.ErrNoRows if there is no such session.
+// The session row is locked between the lookup and the delete, so a concurrent revoke cannot report the same session.
+func (d *mysqlDAL) DeleteSessionReturningUID(dbs DBSession, secret string) (int64, error) {
+	var uid int64
+	if err := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT uid FROM session WHERE secret=? FOR UPDATE`, secret).Scan(&uid); err != nil {
+		return 0, err
+	}
+
+	if _, err := dbs.Tx().ExecContext(dbs.Ctx(), `DELETE FROM session WHERE secret=?`, secret); err != nil {
+		return 0, err
+	}
+
+	return uid, nil
+}
+
 // GetUIDFromSession returns user ID and/or expiration state
 func (d *mysqlDAL) GetUIDFromSession(dbs DBSession, key string) (int64, bool, error) {
-	var row *sql.Row
-	row = dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT uid, expires_at FROM session WHERE secret=?`, key)
+	stmt, err := d.prepared(dbs, `SELECT uid, expires_at FROM session WHERE secret=?`)
+	if err != nil {
+		return 0, false, err
+	}
+	row := stmt.QueryRowContext(dbs.Ctx(), key)
 
 	var uid int64
 	var expiration int64
-	err := row.Scan(&uid, &expiration)
+	err = row.Scan(&uid, &expiration)
 	if err != nil {
 		return 0, false, err
 	}
@@ -116,6 +171,30 @@ func (d *mysqlDAL) GetUIDFromSession(dbs DBSession, key string) (int64, bool, er
 	return uid, true, nil
 }
 
+// ValidateAndExtendSession returns user ID of a valid session and moves its expiration extendBy seconds from now.
+// Expired sessions are not extended. The session row stays locked until the transaction ends.
+func (d *mysqlDAL) ValidateAndExtendSession(dbs DBSession, secret string, extendBy int64) (int64, bool, error) {
+	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT uid, expires_at FROM session WHERE secret=? FOR UPDATE`, secret)
+
+	var uid int64
+	var expiration int64
+	if err := row.Scan(&uid, &expiration); err != nil {
+		return 0, false, err
+	}
+
+	now := time.Now().Unix()
+	if expiration <= now {
+		return 0, false, nil
+	}
+
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `UPDATE session SET expires_at=? WHERE secret=?`, now+extendBy, secret)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return uid, true, nil
+}
+
 // StoreDiscordUser store discord user or replace with new data
 func (d *mysqlDAL) StoreDiscordUser(dbs DBSession, discordUser *types.DiscordUser) error {
 	_, err := dbs.Tx().ExecContext(dbs.Ctx(),
@@ -123,15 +202,31 @@ func (d *mysqlDAL) StoreDiscordUser(dbs DBSession, discordUser *types.DiscordUse
 			   ON DUPLICATE KEY UPDATE username=?, avatar=?, discriminator=?, public_flags=?, flags=?, locale=?, mfa_enabled=?`,
 		discordUser.ID, discordUser.Username, discordUser.Avatar, discordUser.Discriminator, discordUser.PublicFlags, discordUser.Flags, discordUser.Locale, discordUser.MFAEnabled,
 		discordUser.Username, discordUser.Avatar, discordUser.Discriminator, discordUser.PublicFlags, discordUser.Flags, discordUser.Locale, discordUser.MFAEnabled)
+	d.discordUsers.invalidate(discordUser.ID)
 	return err
 }
 
-// GetDiscordUser returns DiscordUserResponse
+// GetDiscordUser returns DiscordUserResponse, possibly from cache, see SetDiscordUserCachePolicy
 func (d *mysqlDAL) GetDiscordUser(dbs DBSession, uid int64) (*types.DiscordUser, error) {
-	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT username, avatar, discriminator, public_flags, flags, locale, mfa_enabled FROM discord_user WHERE id=?`, uid)
+	if discordUser, ok := d.discordUsers.get(uid); ok {
+		return discordUser, nil
+	}
+
+	discordUser, err := d.GetDiscordUserNoCache(dbs, uid)
+	if err != nil {
+		return nil, err
+	}
+	d.discordUsers.put(discordUser)
+
+	return discordUser, nil
+}
+
+// GetDiscordUserNoCache returns DiscordUserResponse straight from the database
+func (d *mysqlDAL) GetDiscordUserNoCache(dbs DBSession, uid int64) (*types.DiscordUser, error) {
+	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT username, avatar, discriminator, public_flags, flags, locale, mfa_enabled, display_name FROM discord_user WHERE id=?`, uid)
 
 	discordUser := &types.DiscordUser{ID: uid}
-	err := row.Scan(&discordUser.Username, &discordUser.Avatar, &discordUser.Discriminator, &discordUser.PublicFlags, &discordUser.Flags, &discordUser.Locale, &discordUser.MFAEnabled)
+	err := row.Scan(&discordUser.Username, &discordUser.Avatar, &discordUser.Discriminator, &discordUser.PublicFlags, &discordUser.Flags, &discordUser.Locale, &discordUser.MFAEnabled, &discordUser.DisplayName)
 	if err != nil {
 		return nil, err
 	}
@@ -139,6 +234,243 @@ func (d *mysqlDAL) GetDiscordUser(dbs DBSession, uid int64) (*types.DiscordUser,
 	return discordUser, nil
 }
 
+// SetDisplayName sets name shown instead of the discord username, empty name clears it
+func (d *mysqlDAL) SetDisplayName(dbs DBSession, uid int64, name string) error {
+	var displayName *string
+	name = strings.TrimSpace(name)
+	if len(name) > 0 {
+		if utf8.RuneCountInString(name) > maxDisplayNameLength {
+			return fmt.Errorf(constants.ErrorDisplayNameTooLong)
+		}
+		displayName = &name
+	}
+
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `UPDATE discord_user SET display_name = ? WHERE id = ?`, displayName, uid)
+	d.discordUsers.invalidate(uid)
+	return err
+}
+
+// AnonymizeUser irreversibly forgets a user: everything they authored is handed over to constants.AnonymizedUserID with its content intact,
+// their sessions, roles, settings, subscriptions and reactions are deleted, and so is the user row itself.
+// Returns sql.ErrNoRows if the user does not exist.
+func (d *mysqlDAL) AnonymizeUser(dbs DBSession, uid int64) (*types.UserAnonymization, error) {
+	if uid == constants.ValidatorID || uid == constants.SystemID || uid == constants.AnonymizedUserID {
+		return nil, fmt.Errorf(constants.ErrorCannotAnonymizeUser)
+	}
+
+	var exists int64
+	if err := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT id FROM discord_user WHERE id = ? FOR UPDATE`, uid).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	result := &types.UserAnonymization{
+		ReassignedRows: make(map[string]int64),
+		DeletedRows:    make(map[string]int64),
+	}
+
+	// the cache holds user IDs, so it has to be recomputed for every submission the user touched
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT fk_submission_id FROM comment WHERE fk_user_id = ? OR fk_pinned_by_id = ?
+		UNION
+		SELECT fk_submission_id FROM submission_file WHERE fk_user_id = ?
+		UNION
+		SELECT id FROM submission WHERE fk_owner_id = ?
+		ORDER BY 1`,
+		uid, uid, uid, uid)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var sid int64
+		if err := rows.Scan(&sid); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		result.SubmissionIDs = append(result.SubmissionIDs, sid)
+	}
+	rows.Close()
+
+	reassigned := []struct{ table, column string }{
+		{"submission", "fk_owner_id"},
+		{"submission_file", "fk_user_id"},
+		{"comment", "fk_user_id"},
+		{"comment", "fk_pinned_by_id"},
+		{"flashfreeze_file", "fk_user_id"},
+		{"fixes", "fk_user_id"},
+		{"fixes", "fk_deleted_by_user_id"},
+		{"fixes_file", "fk_user_id"},
+		{"fixes_file", "fk_deleted_by_user_id"},
+		{"status_transition", "fk_actor_id"},
+		{"submission_archive", "fk_owner_id"},
+		{"submission_file_archive", "fk_user_id"},
+		{"comment_archive", "fk_user_id"},
+		{"comment_archive", "fk_pinned_by_id"},
+		{"status_transition_archive", "fk_actor_id"},
+	}
+	for _, r := range reassigned {
+		res, err := dbs.Tx().ExecContext(dbs.Ctx(), `UPDATE `+r.table+` SET `+r.column+` = ? WHERE `+r.column+` = ?`, constants.AnonymizedUserID, uid)
+		if err != nil {
+			return nil, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		result.ReassignedRows[r.table+"."+r.column] = n
+	}
+
+	deleted := []struct{ table, column string }{
+		{"session", "uid"},
+		{"discord_user_role", "fk_uid"},
+		{"notification_settings", "fk_user_id"},
+		{"submission_notification_subscription", "fk_user_id"},
+		{"comment_reaction", "fk_user_id"},
+		{"file_download", "fk_user_id"},
+		{"file_download_archive", "fk_user_id"},
+		{"reviewer_note", "fk_user_id"},
+		{"submission_view", "fk_user_id"},
+		{"discord_user", "id"},
+	}
+	for _, r := range deleted {
+		res, err := dbs.Tx().ExecContext(dbs.Ctx(), `DELETE FROM `+r.table+` WHERE `+r.column+` = ?`, uid)
+		if err != nil {
+			return nil, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		result.DeletedRows[r.table] = n
+	}
+	d.discordUsers.invalidate(uid)
+
+	for _, sid := range result.SubmissionIDs {
+		if err := d.UpdateSubmissionCacheTable(dbs, sid); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// DeauthorizeUserFully takes away all discord roles of a user, logs them out and releases every review they are assigned to,
+// recording the same unassign comments the user would leave. Their watches are removed too if removeWatches is set.
+// Discord stays the source of truth for roles: nothing is blocked here, the roles are stored again on the next login,
+// so the roles have to be taken away on the Discord server as well. Returns sql.ErrNoRows if the user does not exist.
+func (d *mysqlDAL) DeauthorizeUserFully(dbs DBSession, uid int64, removeWatches bool) (*types.UserDeauthorization, error) {
+	var exists int64
+	if err := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT id FROM discord_user WHERE id = ? FOR UPDATE`, uid).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	result := &types.UserDeauthorization{
+		SubmissionIDs: make([]int64, 0),
+	}
+
+	res, err := dbs.Tx().ExecContext(dbs.Ctx(), `DELETE FROM discord_user_role WHERE fk_uid = ?`, uid)
+	if err != nil {
+		return nil, err
+	}
+	if result.RolesRemoved, err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+
+	if result.SessionsRevoked, err = d.DeleteUserSessions(dbs, uid); err != nil {
+		return nil, err
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT submission.id,
+		       FIND_IN_SET(?, COALESCE(submission_cache.active_assigned_testing_ids, '')) > 0,
+		       FIND_IN_SET(?, COALESCE(submission_cache.active_assigned_verification_ids, '')) > 0
+		FROM submission
+		JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+		WHERE submission.deleted_at IS NULL
+		AND (FIND_IN_SET(?, submission_cache.active_assigned_testing_ids) OR FIND_IN_SET(?, submission_cache.active_assigned_verification_ids))
+		ORDER BY submission.id
+		FOR UPDATE`,
+		uid, uid, uid, uid)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	data := make([]interface{}, 0)
+	for rows.Next() {
+		var sid int64
+		var testing, verification bool
+		if err := rows.Scan(&sid, &testing, &verification); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if testing {
+			data = append(data, uid, sid, constants.ActionUnassignTesting, now)
+			result.UnassignedTesting++
+		}
+		if verification {
+			data = append(data, uid, sid, constants.ActionUnassignVerification, now)
+			result.UnassignedVerification++
+		}
+		result.SubmissionIDs = append(result.SubmissionIDs, sid)
+	}
+	rows.Close()
+
+	if len(data) > 0 {
+		const valuePlaceholder = `(?, ?, (SELECT id FROM action WHERE name=?), ?)`
+		_, err = d.execWithRetry(dbs,
+			`INSERT INTO comment (fk_user_id, fk_submission_id, fk_action_id, created_at) VALUES `+valuePlaceholder+strings.Repeat(`,`+valuePlaceholder, len(data)/4-1),
+			data...)
+		if err != nil {
+			return nil, err
+		}
+		for _, sid := range result.SubmissionIDs {
+			if err := d.RecomputeSubmissionDerivedFields(dbs, sid); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if removeWatches {
+		res, err := dbs.Tx().ExecContext(dbs.Ctx(), `DELETE FROM submission_notification_subscription WHERE fk_user_id = ?`, uid)
+		if err != nil {
+			return nil, err
+		}
+		if result.WatchesRemoved, err = res.RowsAffected(); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// GetInactiveAuthorizedUsers returns users holding any discord role who have not uploaded a file or commented since a given time.
+// Join time of users is not tracked, so users who joined after the cutoff are returned as well.
+func (d *mysqlDAL) GetInactiveAuthorizedUsers(dbs DBSession, since time.Time) ([]*types.DiscordUser, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT id, username, avatar, discriminator, public_flags, flags, locale, mfa_enabled FROM discord_user
+		WHERE EXISTS (SELECT 1 FROM discord_user_role WHERE discord_user_role.fk_uid = discord_user.id)
+		AND NOT EXISTS (SELECT 1 FROM submission_file WHERE submission_file.fk_user_id = discord_user.id AND submission_file.created_at >= ?)
+		AND NOT EXISTS (SELECT 1 FROM comment WHERE comment.fk_user_id = discord_user.id AND comment.created_at >= ?)
+		AND id != ?
+		ORDER BY id`,
+		since.Unix(), since.Unix(), constants.ValidatorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*types.DiscordUser, 0)
+
+	for rows.Next() {
+		discordUser := &types.DiscordUser{}
+		if err := rows.Scan(&discordUser.ID, &discordUser.Username, &discordUser.Avatar, &discordUser.Discriminator, &discordUser.PublicFlags, &discordUser.Flags, &discordUser.Locale, &discordUser.MFAEnabled); err != nil {
+			return nil, err
+		}
+		result = append(result, discordUser)
+	}
+
+	return result, nil
+}
+
 // StoreDiscordServerRoles store discord user or replace with new data
 func (d *mysqlDAL) StoreDiscordServerRoles(dbs DBSession, roles []types.DiscordRole) error {
 	if len(roles) == 0 {
@@ -180,8 +512,12 @@ func (d *mysqlDAL) StoreDiscordUserRoles(dbs DBSession, uid int64, roles []int64
 
 // GetDiscordUserRoles returns all user roles
 func (d *mysqlDAL) GetDiscordUserRoles(dbs DBSession, uid int64) ([]string, error) {
-	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
-		SELECT (SELECT name FROM discord_role WHERE discord_role.id=discord_user_role.fk_rid) FROM discord_user_role WHERE fk_uid=?`, uid)
+	stmt, err := d.prepared(dbs, `
+		SELECT (SELECT name FROM discord_role WHERE discord_role.id=discord_user_role.fk_rid) FROM discord_user_role WHERE fk_uid=?`)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := stmt.QueryContext(dbs.Ctx(), uid)
 	if err != nil {
 		return nil, err
 	}
@@ -200,11 +536,135 @@ func (d *mysqlDAL) GetDiscordUserRoles(dbs DBSession, uid int64) ([]string, erro
 	return result, nil
 }
 
-// StoreSubmission stores plain submission
-func (d *mysqlDAL) StoreSubmission(dbs DBSession, submissionLevel string) (int64, error) {
-	res, err := dbs.Tx().ExecContext(dbs.Ctx(), `INSERT INTO submission (fk_submission_level_id) 
-				VALUES ((SELECT id FROM submission_level WHERE name = ?))`,
-		submissionLevel)
+// GetDiscordUsersRoles returns roles of multiple users in one query, users without roles map to an empty slice
+func (d *mysqlDAL) GetDiscordUsersRoles(dbs DBSession, uids []int64) (map[int64][]string, error) {
+	result := make(map[int64][]string, len(uids))
+	if len(uids) == 0 {
+		return result, nil
+	}
+
+	data := make([]interface{}, 0, len(uids))
+	for _, uid := range uids {
+		result[uid] = []string{}
+		data = append(data, uid)
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT discord_user_role.fk_uid, discord_role.name FROM discord_user_role
+		JOIN discord_role ON discord_role.id = discord_user_role.fk_rid
+		WHERE discord_user_role.fk_uid IN (?`+strings.Repeat(",?", len(uids)-1)+`)`,
+		data...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var uid int64
+		var name string
+		if err := rows.Scan(&uid, &name); err != nil {
+			return nil, err
+		}
+		result[uid] = append(result[uid], name)
+	}
+
+	return result, nil
+}
+
+// SyncDiscordRoleMembers makes the given users the only members of a discord role and returns users whose membership changed.
+// If grantOnly is set, current members missing from uids keep the role. Unknown users are skipped.
+func (d *mysqlDAL) SyncDiscordRoleMembers(dbs DBSession, rid int64, uids []int64, grantOnly bool) ([]int64, error) {
+	queryIDs := func(query string, args ...interface{}) (map[int64]bool, error) {
+		rows, err := dbs.Tx().QueryContext(dbs.Ctx(), query, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		result := make(map[int64]bool)
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				return nil, err
+			}
+			result[id] = true
+		}
+		return result, nil
+	}
+
+	// lock current memberships so concurrent syncs of the same role serialize
+	members, err := queryIDs(`SELECT fk_uid FROM discord_user_role WHERE fk_rid = ? FOR UPDATE`, rid)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[int64]bool, len(uids))
+	candidates := make([]interface{}, 0, len(uids))
+	for _, uid := range uids {
+		if wanted[uid] {
+			continue
+		}
+		wanted[uid] = true
+		if !members[uid] {
+			candidates = append(candidates, uid)
+		}
+	}
+
+	changed := make([]int64, 0)
+
+	if len(candidates) > 0 {
+		existing, err := queryIDs(`SELECT id FROM discord_user WHERE id IN (?`+strings.Repeat(",?", len(candidates)-1)+`)`, candidates...)
+		if err != nil {
+			return nil, err
+		}
+
+		data := make([]interface{}, 0, len(existing)*2)
+		for uid := range existing {
+			data = append(data, uid, rid)
+			changed = append(changed, uid)
+		}
+
+		if len(existing) > 0 {
+			const valuePlaceholder = `(?, ?)`
+			_, err = dbs.Tx().ExecContext(dbs.Ctx(),
+				`INSERT INTO discord_user_role (fk_uid, fk_rid) VALUES `+valuePlaceholder+strings.Repeat(`,`+valuePlaceholder, len(existing)-1),
+				data...)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !grantOnly {
+		data := []interface{}{rid}
+		for uid := range members {
+			if !wanted[uid] {
+				data = append(data, uid)
+				changed = append(changed, uid)
+			}
+		}
+
+		if len(data) > 1 {
+			_, err = dbs.Tx().ExecContext(dbs.Ctx(),
+				`DELETE FROM discord_user_role WHERE fk_rid = ? AND fk_uid IN (?`+strings.Repeat(",?", len(data)-2)+`)`,
+				data...)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.Slice(changed, func(i, j int) bool { return changed[i] < changed[j] })
+
+	return changed, nil
+}
+
+// StoreSubmission stores plain submission.
+// Submissions are published by default, isDraft keeps a new submission hidden from reviewers until PublishSubmission is called.
+func (d *mysqlDAL) StoreSubmission(dbs DBSession, submissionLevel string, isDraft bool) (int64, error) {
+	res, err := d.execWithRetry(dbs, `INSERT INTO submission (fk_submission_level_id, is_draft) 
+				VALUES ((SELECT id FROM submission_level WHERE name = ?), ?)`,
+		submissionLevel, isDraft)
 	if err != nil {
 		return 0, err
 	}
@@ -213,7 +673,7 @@ func (d *mysqlDAL) StoreSubmission(dbs DBSession, submissionLevel string) (int64
 		return 0, err
 	}
 
-	_, err = dbs.Tx().ExecContext(dbs.Ctx(), `
+	_, err = d.execWithRetry(dbs, `
 		INSERT INTO submission_cache (fk_submission_id) 
 		VALUES (?)`,
 		sid)
@@ -224,194 +684,1508 @@ func (d *mysqlDAL) StoreSubmission(dbs DBSession, submissionLevel string) (int64
 	return sid, nil
 }
 
-// StoreSubmissionFile stores submission file
-func (d *mysqlDAL) StoreSubmissionFile(dbs DBSession, s *types.SubmissionFile) (int64, error) {
-	res, err := dbs.Tx().ExecContext(dbs.Ctx(), `INSERT INTO submission_file (fk_user_id, fk_submission_id, original_filename, current_filename, size, created_at, md5sum, sha256sum) 
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
-		s.SubmitterID, s.SubmissionID, s.OriginalFilename, s.CurrentFilename, s.Size, s.UploadedAt.Unix(), s.MD5Sum, s.SHA256Sum)
-	if err != nil {
-		return 0, err
-	}
-	fid, err := res.LastInsertId()
-	if err != nil {
-		return 0, err
+// TransferSubmissionOwnership sets the explicit owner of a submission and records the transfer as a system comment
+func (d *mysqlDAL) TransferSubmissionOwnership(dbs DBSession, sid, newOwnerUID int64) error {
+	var exists int64
+	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT id FROM submission WHERE id = ?`, sid)
+	if err := row.Scan(&exists); err != nil {
+		return err
 	}
 
-	return fid, nil
-}
-
-// GetSubmissionFiles gets submission files, returns error if input len != output len
-func (d *mysqlDAL) GetSubmissionFiles(dbs DBSession, sfids []int64) ([]*types.SubmissionFile, error) {
-	if len(sfids) == 0 {
-		return nil, nil
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
+		UPDATE submission SET fk_owner_id = ?
+		WHERE id = ?`,
+		newOwnerUID, sid)
+	if err != nil {
+		return err
 	}
 
-	data := make([]interface{}, len(sfids))
-	for i, d := range sfids {
-		data[i] = d
+	msg := fmt.Sprintf("Submission ownership transferred to user %d", newOwnerUID)
+	err = d.StoreComment(dbs, &types.Comment{
+		AuthorID:     constants.SystemID,
+		SubmissionID: sid,
+		Message:      &msg,
+		Action:       constants.ActionSystem,
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		return err
 	}
 
-	q := `
-		SELECT fk_user_id, fk_submission_id, original_filename, current_filename, size, created_at, md5sum, sha256sum 
-		FROM submission_file 
-		WHERE id IN(?` + strings.Repeat(",?", len(sfids)-1) + `)
-		AND deleted_at IS NULL
-		ORDER BY created_at DESC`
-
-	var rows *sql.Rows
-	var err error
-	rows, err = dbs.Tx().QueryContext(dbs.Ctx(), q, data...)
+	err = d.UpdateSubmissionCacheTable(dbs, sid)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var result = make([]*types.SubmissionFile, 0, len(sfids))
-	for rows.Next() {
-		sf := &types.SubmissionFile{}
-		var uploadedAt int64
-		err := rows.Scan(&sf.SubmitterID, &sf.SubmissionID, &sf.OriginalFilename, &sf.CurrentFilename, &sf.Size, &uploadedAt, &sf.MD5Sum, &sf.SHA256Sum)
-		if err != nil {
-			return nil, err
-		}
-		sf.UploadedAt = time.Unix(uploadedAt, 0)
-		result = append(result, sf)
-	}
+	return nil
+}
 
-	if len(result) != len(sfids) {
-		return nil, fmt.Errorf("%d files were not found", len(result)-len(sfids))
+// PublishSubmission makes a draft submission visible to reviewers, returns sql.ErrNoRows if the submission does not exist
+func (d *mysqlDAL) PublishSubmission(dbs DBSession, sid int64) error {
+	var isDraft bool
+	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT is_draft FROM submission WHERE id = ? FOR UPDATE`, sid)
+	if err := row.Scan(&isDraft); err != nil {
+		return err
+	}
+	if !isDraft {
+		return nil
+	}
+
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
+		UPDATE submission SET is_draft = FALSE
+		WHERE id = ?`,
+		sid)
+	return err
+}
+
+// SetReviewDeadline sets the review deadline of a submission, as a unix timestamp
+func (d *mysqlDAL) SetReviewDeadline(dbs DBSession, sid, deadline int64) error {
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
+		UPDATE submission SET review_deadline = ?
+		WHERE id = ?`,
+		deadline, sid)
+	return err
+}
+
+// SetSubmissionPriority sets queue priority of a submission, higher goes first
+func (d *mysqlDAL) SetSubmissionPriority(dbs DBSession, sid, priority int64) error {
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
+		UPDATE submission SET priority = ?
+		WHERE id = ?`,
+		priority, sid)
+	return err
+}
+
+// SetSubmissionStatus sets review status of a submission, see constants.GetAllowedSubmissionStatuses.
+// A change of the status is recorded as a status transition done by the given user, setting the current status again does nothing.
+// Changes not allowed by constants.GetAllowedStatusTransitions are rejected.
+func (d *mysqlDAL) SetSubmissionStatus(dbs DBSession, sid int64, status string, actorUID int64) error {
+	return d.setSubmissionStatus(dbs, sid, status, actorUID, true)
+}
+
+// setSubmissionStatus is SetSubmissionStatus which only checks the legality of the change if checkTransition is set.
+// Statuses derived from the source rows are recorded as they are, the rows they follow from were already accepted.
+func (d *mysqlDAL) setSubmissionStatus(dbs DBSession, sid int64, status string, actorUID int64, checkTransition bool) error {
+	allowed := false
+	for _, s := range constants.GetAllowedSubmissionStatuses() {
+		if status == s {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf(constants.ErrorInvalidSubmissionStatus)
+	}
+
+	var current string
+	err := dbs.Tx().QueryRowContext(dbs.Ctx(), `
+		SELECT status FROM submission
+		WHERE id = ?
+		FOR UPDATE`,
+		sid).Scan(&current)
+	if err != nil {
+		return err
+	}
+	if current == status {
+		return nil
+	}
+
+	if checkTransition && !constants.IsStatusTransitionAllowed(current, status) {
+		return fmt.Errorf(constants.ErrorInvalidStatusTransition)
+	}
+
+	if err := d.recordStatusTransition(dbs, sid, current, status, actorUID); err != nil {
+		return err
+	}
+
+	_, err = d.execWithRetry(dbs, `
+		UPDATE submission SET status = ?
+		WHERE id = ?`,
+		status, sid)
+	return err
+}
+
+// RecordStatusTransition stores a change of review status of a submission, see constants.GetAllowedStatusTransitions
+func (d *mysqlDAL) RecordStatusTransition(dbs DBSession, sid int64, from, to string, actorUID int64) error {
+	if !constants.IsStatusTransitionAllowed(from, to) {
+		return fmt.Errorf(constants.ErrorInvalidStatusTransition)
+	}
+	return d.recordStatusTransition(dbs, sid, from, to, actorUID)
+}
+
+func (d *mysqlDAL) recordStatusTransition(dbs DBSession, sid int64, from, to string, actorUID int64) error {
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
+		INSERT INTO status_transition (fk_submission_id, from_status, to_status, fk_actor_id, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		sid, from, to, actorUID, time.Now().Unix())
+	return err
+}
+
+// GetStatusTransitions returns the review status history of a submission, oldest first
+func (d *mysqlDAL) GetStatusTransitions(dbs DBSession, sid int64) ([]*types.StatusTransition, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT status_transition.id, status_transition.fk_submission_id, status_transition.from_status, status_transition.to_status,
+		       status_transition.fk_actor_id, discord_user.username, status_transition.created_at
+		FROM status_transition
+		JOIN discord_user ON discord_user.id = status_transition.fk_actor_id
+		WHERE status_transition.fk_submission_id = ?
+		ORDER BY status_transition.created_at, status_transition.id`,
+		sid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*types.StatusTransition, 0)
+	for rows.Next() {
+		st := &types.StatusTransition{}
+		var createdAt int64
+		if err := rows.Scan(&st.ID, &st.SubmissionID, &st.FromStatus, &st.ToStatus, &st.ActorID, &st.ActorUsername, &createdAt); err != nil {
+			return nil, err
+		}
+		st.CreatedAt = time.Unix(createdAt, 0)
+		result = append(result, st)
+	}
+
+	return result, nil
+}
+
+// IncrementSubmissionViews counts a view of a submission, a user viewing the same submission again on the same day is not counted
+func (d *mysqlDAL) IncrementSubmissionViews(dbs DBSession, sid, uid int64) error {
+	res, err := dbs.Tx().ExecContext(dbs.Ctx(), `
+		INSERT IGNORE INTO submission_view (fk_submission_id, fk_user_id, view_date)
+		VALUES (?, ?, UTC_DATE())`,
+		sid, uid)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+
+	_, err = d.execWithRetry(dbs, `UPDATE submission SET view_count = view_count + 1 WHERE id = ?`, sid)
+	return err
+}
+
+// GetSubmissionViews returns the number of distinct viewers of a submission per day, newest day first, days without views are left out
+func (d *mysqlDAL) GetSubmissionViews(dbs DBSession, sid int64) ([]*types.DailyViewCount, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT DATE_FORMAT(view_date, '%Y-%m-%d'), COUNT(*) FROM submission_view
+		WHERE fk_submission_id = ?
+		GROUP BY view_date
+		ORDER BY view_date DESC`,
+		sid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*types.DailyViewCount, 0)
+
+	var date string
+
+	for rows.Next() {
+		dvc := &types.DailyViewCount{}
+		if err := rows.Scan(&date, &dvc.Viewers); err != nil {
+			return nil, err
+		}
+		dvc.Date, err = time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, dvc)
+	}
+
+	return result, nil
+}
+
+// MarkSubmissionAccepted records that a submission was exported into the main flashpoint database as a given game and sets its status to accepted.
+// Fails if the submission is already accepted, returns sql.ErrNoRows if it does not exist.
+func (d *mysqlDAL) MarkSubmissionAccepted(dbs DBSession, sid int64, gameID string) error {
+	var acceptedAt *int64
+	err := dbs.Tx().QueryRowContext(dbs.Ctx(), `
+		SELECT accepted_at FROM submission
+		WHERE id = ?
+		FOR UPDATE`,
+		sid).Scan(&acceptedAt)
+	if err != nil {
+		return err
+	}
+	if acceptedAt != nil {
+		return fmt.Errorf(constants.ErrorSubmissionAlreadyAccepted)
+	}
+
+	_, err = d.execWithRetry(dbs, `
+		UPDATE submission SET accepted_at = ?, accepted_game_id = ?
+		WHERE id = ?`,
+		time.Now().Unix(), gameID, sid)
+	if err != nil {
+		return err
+	}
+
+	return d.SetSubmissionStatus(dbs, sid, constants.SubmissionStatusAccepted, constants.SystemID)
+}
+
+// SetSubmissionMeta stores a metadata value of a submission, replacing the current value of the key
+func (d *mysqlDAL) SetSubmissionMeta(dbs DBSession, sid int64, key, value string) error {
+	_, err := d.execWithRetry(dbs, `
+		INSERT INTO submission_meta (fk_submission_id, meta_key, meta_value)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE meta_value = VALUES(meta_value)`,
+		sid, key, value)
+	return err
+}
+
+// GetSubmissionMeta returns all metadata of a submission
+func (d *mysqlDAL) GetSubmissionMeta(dbs DBSession, sid int64) (map[string]string, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT meta_key, meta_value FROM submission_meta
+		WHERE fk_submission_id = ?`,
+		sid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+// DeleteSubmissionMeta removes a metadata key of a submission
+func (d *mysqlDAL) DeleteSubmissionMeta(dbs DBSession, sid int64, key string) error {
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
+		DELETE FROM submission_meta
+		WHERE fk_submission_id = ? AND meta_key = ?`,
+		sid, key)
+	return err
+}
+
+// StoreSubmissionFile stores submission file
+func (d *mysqlDAL) StoreSubmissionFile(dbs DBSession, s *types.SubmissionFile) (int64, error) {
+	res, err := d.execWithRetry(dbs, `INSERT INTO submission_file (fk_user_id, fk_submission_id, original_filename, current_filename, size, created_at, md5sum, sha256sum) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		s.SubmitterID, s.SubmissionID, s.OriginalFilename, s.CurrentFilename, s.Size, s.UploadedAt.Unix(), s.MD5Sum, s.SHA256Sum)
+	if err != nil {
+		return 0, err
+	}
+	fid, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return fid, nil
+}
+
+// StoreSubmissionFileWithQuota stores submission file if it fits into the uploader's storage quota, zero quota means unlimited
+func (d *mysqlDAL) StoreSubmissionFileWithQuota(dbs DBSession, s *types.SubmissionFile, quotaBytes int64) (int64, error) {
+	if quotaBytes > 0 {
+		// lock the uploader so concurrent uploads of the same user cannot both pass the check
+		var uid int64
+		row := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT id FROM discord_user WHERE id = ? FOR UPDATE`, s.SubmitterID)
+		if err := row.Scan(&uid); err != nil {
+			return 0, err
+		}
+
+		used, err := d.GetStorageUsedByUser(dbs, s.SubmitterID, true)
+		if err != nil {
+			return 0, err
+		}
+		if used+s.Size > quotaBytes {
+			return 0, fmt.Errorf(constants.ErrorQuotaExceeded)
+		}
+	}
+
+	return d.StoreSubmissionFile(dbs, s)
+}
+
+// StoreSubmissionFileWithLimit stores submission file unless its submission already has maxFiles files, zero maxFiles means unlimited
+func (d *mysqlDAL) StoreSubmissionFileWithLimit(dbs DBSession, s *types.SubmissionFile, maxFiles int) (int64, error) {
+	if maxFiles > 0 {
+		// lock the submission so concurrent uploads to it cannot both pass the check
+		var sid int64
+		row := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT id FROM submission WHERE id = ? FOR UPDATE`, s.SubmissionID)
+		if err := row.Scan(&sid); err != nil {
+			return 0, err
+		}
+
+		count, err := d.CountSubmissionFiles(dbs, s.SubmissionID)
+		if err != nil {
+			return 0, err
+		}
+		if count >= maxFiles {
+			return 0, fmt.Errorf(constants.ErrorSubmissionFileLimitReached)
+		}
+	}
+
+	return d.StoreSubmissionFile(dbs, s)
+}
+
+// CountSubmissionFiles returns the number of files of a submission which are not deleted
+func (d *mysqlDAL) CountSubmissionFiles(dbs DBSession, sid int64) (int, error) {
+	var count int
+	err := dbs.Tx().QueryRowContext(dbs.Ctx(), `
+		SELECT COUNT(*) FROM submission_file
+		WHERE fk_submission_id = ? AND deleted_at IS NULL`,
+		sid).Scan(&count)
+	return count, err
+}
+
+// RecordFileDownload records that a user downloaded a submission file from a given address
+func (d *mysqlDAL) RecordFileDownload(dbs DBSession, sfid, uid int64, ip string) error {
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
+		INSERT INTO file_download (fk_submission_file_id, fk_user_id, ip, downloaded_at)
+		VALUES (?, ?, ?, ?)`,
+		sfid, uid, ip, time.Now().Unix())
+	return err
+}
+
+// GetFileDownloadHistory returns downloads of a submission file, newest first
+func (d *mysqlDAL) GetFileDownloadHistory(dbs DBSession, sfid int64) ([]*types.FileDownload, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT file_download.id, file_download.fk_submission_file_id, file_download.fk_user_id, COALESCE(display_name, username), file_download.ip, file_download.downloaded_at
+		FROM file_download
+		JOIN discord_user ON discord_user.id = file_download.fk_user_id
+		WHERE file_download.fk_submission_file_id = ?
+		ORDER BY file_download.downloaded_at DESC, file_download.id DESC`,
+		sfid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*types.FileDownload, 0)
+
+	var downloadedAt int64
+
+	for rows.Next() {
+		fd := &types.FileDownload{}
+		if err := rows.Scan(&fd.ID, &fd.SubmissionFileID, &fd.UserID, &fd.Username, &fd.IP, &downloadedAt); err != nil {
+			return nil, err
+		}
+		fd.DownloadedAt = time.Unix(downloadedAt, 0)
+		result = append(result, fd)
+	}
+
+	return result, nil
+}
+
+// GetDownloadCount returns how many times a submission file was downloaded
+func (d *mysqlDAL) GetDownloadCount(dbs DBSession, sfid int64) (int64, error) {
+	var count int64
+	err := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT COUNT(*) FROM file_download WHERE fk_submission_file_id = ?`, sfid).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetSubmissionFiles gets submission files, returns error if input len != output len
+func (d *mysqlDAL) GetSubmissionFiles(dbs DBSession, sfids []int64) ([]*types.SubmissionFile, error) {
+	if len(sfids) == 0 {
+		return nil, nil
+	}
+
+	data := make([]interface{}, len(sfids))
+	for i, d := range sfids {
+		data[i] = d
+	}
+
+	q := `
+		SELECT fk_user_id, fk_submission_id, original_filename, current_filename, size, created_at, md5sum, sha256sum 
+		FROM submission_file 
+		WHERE id IN(?` + strings.Repeat(",?", len(sfids)-1) + `)
+		AND deleted_at IS NULL
+		ORDER BY created_at DESC`
+
+	var rows *sql.Rows
+	var err error
+	rows, err = dbs.Tx().QueryContext(dbs.Ctx(), q, data...)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = make([]*types.SubmissionFile, 0, len(sfids))
+	for rows.Next() {
+		sf := &types.SubmissionFile{}
+		var uploadedAt int64
+		err := rows.Scan(&sf.SubmitterID, &sf.SubmissionID, &sf.OriginalFilename, &sf.CurrentFilename, &sf.Size, &uploadedAt, &sf.MD5Sum, &sf.SHA256Sum)
+		if err != nil {
+			return nil, err
+		}
+		sf.UploadedAt = time.Unix(uploadedAt, 0)
+		result = append(result, sf)
+	}
+
+	if len(result) != len(sfids) {
+		return nil, fmt.Errorf("%d files were not found", len(result)-len(sfids))
+	}
+
+	return result, nil
+}
+
+// GetExtendedSubmissionFilesBySubmissionID returns all extended submission files for a given submission
+func (d *mysqlDAL) GetExtendedSubmissionFilesBySubmissionID(dbs DBSession, sid int64) ([]*types.ExtendedSubmissionFile, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT submission_file.id, fk_user_id, COALESCE(display_name, username), avatar, 
+		       original_filename, current_filename, size, created_at, md5sum, sha256sum 
+		FROM submission_file 
+		LEFT JOIN discord_user ON fk_user_id=discord_user.id
+		WHERE fk_submission_id=?
+		AND submission_file.deleted_at IS NULL
+		ORDER BY created_at DESC`, sid)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*types.ExtendedSubmissionFile, 0)
+	var avatar string
+	var uploadedAt int64
+	for rows.Next() {
+		sf := &types.ExtendedSubmissionFile{SubmissionID: sid}
+		err := rows.Scan(&sf.FileID, &sf.SubmitterID, &sf.SubmitterUsername, &avatar,
+			&sf.OriginalFilename, &sf.CurrentFilename, &sf.Size, &uploadedAt, &sf.MD5Sum, &sf.SHA256Sum)
+		if err != nil {
+			return nil, err
+		}
+		sf.SubmitterAvatarURL = utils.FormatAvatarURL(sf.SubmitterID, avatar)
+		sf.UploadedAt = time.Unix(uploadedAt, 0)
+		result = append(result, sf)
+	}
+	return result, nil
+}
+
+// GetOrphanedSubmissionFiles returns submission files whose submission does not exist or is deleted while the file is not
+func (d *mysqlDAL) GetOrphanedSubmissionFiles(dbs DBSession) ([]*types.SubmissionFile, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT submission_file.fk_user_id, submission_file.fk_submission_id, original_filename, current_filename, size, created_at, md5sum, sha256sum
+		FROM submission_file
+		LEFT JOIN submission ON submission.id = submission_file.fk_submission_id
+		WHERE submission.id IS NULL
+		OR (submission.deleted_at IS NOT NULL AND submission_file.deleted_at IS NULL)
+		ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result = make([]*types.SubmissionFile, 0)
+	for rows.Next() {
+		sf := &types.SubmissionFile{}
+		var uploadedAt int64
+		err := rows.Scan(&sf.SubmitterID, &sf.SubmissionID, &sf.OriginalFilename, &sf.CurrentFilename, &sf.Size, &uploadedAt, &sf.MD5Sum, &sf.SHA256Sum)
+		if err != nil {
+			return nil, err
+		}
+		sf.UploadedAt = time.Unix(uploadedAt, 0)
+		result = append(result, sf)
+	}
+
+	return result, nil
+}
+
+// GetSubmissionsWithNoFiles returns IDs of submissions that are not deleted but have no files that are not deleted
+func (d *mysqlDAL) GetSubmissionsWithNoFiles(dbs DBSession) ([]int64, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT submission.id
+		FROM submission
+		WHERE submission.deleted_at IS NULL
+		AND NOT EXISTS (
+		    SELECT 1 FROM submission_file 
+		    WHERE submission_file.fk_submission_id = submission.id 
+		    AND submission_file.deleted_at IS NULL)
+		ORDER BY submission.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]int64, 0)
+	var sid int64
+
+	for rows.Next() {
+		if err := rows.Scan(&sid); err != nil {
+			return nil, err
+		}
+		result = append(result, sid)
+	}
+
+	return result, nil
+}
+
+// normalizeCurationMetaFields returns the normalized forms of curation meta source and launch command, which are stored next to the raw values
+func normalizeCurationMetaFields(source, launchCommand *string) (*string, *string) {
+	var normalizedSource *string
+	if source != nil {
+		ns := utils.NormalizeSourceURL(*source)
+		normalizedSource = &ns
+	}
+	var normalizedLaunchCommand *string
+	if launchCommand != nil {
+		if nlc := strings.TrimSpace(*launchCommand); len(nlc) > 0 {
+			normalizedLaunchCommand = &nlc
+		}
+	}
+	return normalizedSource, normalizedLaunchCommand
+}
+
+// StoreCurationMeta stores curation meta
+func (d *mysqlDAL) StoreCurationMeta(dbs DBSession, cm *types.CurationMeta) error {
+	normalizedSource, normalizedLaunchCommand := normalizeCurationMetaFields(cm.Source, cm.LaunchCommand)
+
+	now := time.Now().Unix()
+
+	_, err := d.execWithRetry(dbs, `INSERT INTO curation_meta (fk_submission_file_id, application_path, developer, extreme, game_notes, languages,
+                           launch_command, original_description, play_mode, platform, publisher, release_date, series, source, status,
+                           tags, tag_categories, title, alternate_titles, library, version, curation_notes, mount_parameters, normalized_source,
+                           normalized_launch_command, created_at, updated_at)
+                           VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		cm.SubmissionFileID, cm.ApplicationPath, cm.Developer, cm.Extreme, cm.GameNotes, cm.Languages,
+		cm.LaunchCommand, cm.OriginalDescription, cm.PlayMode, cm.Platform, cm.Publisher, cm.ReleaseDate, cm.Series, cm.Source, cm.Status,
+		cm.Tags, cm.TagCategories, cm.Title, cm.AlternateTitles, cm.Library, cm.Version, cm.CurationNotes, cm.MountParameters, normalizedSource,
+		normalizedLaunchCommand, now, now)
+	if err != nil {
+		return err
+	}
+	cm.CreatedAt = time.Unix(now, 0)
+	cm.UpdatedAt = cm.CreatedAt
+
+	if cm.Tags != nil {
+		if err := storeCurationTags(dbs, cm.SubmissionFileID, utils.SplitCurationTags(*cm.Tags)); err != nil {
+			return err
+		}
+	}
+	if cm.Languages != nil {
+		if err := storeCurationLanguages(dbs, cm.SubmissionFileID, utils.SplitCurationLanguages(*cm.Languages)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// storeCurationTags stores normalized curation tags of a given submission file into the tag lookup table
+func storeCurationTags(dbs DBSession, sfid int64, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	data := make([]interface{}, 0, len(tags)*2)
+	for _, tag := range tags {
+		data = append(data, sfid, tag)
+	}
+
+	const valuePlaceholder = `(?, ?)`
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(),
+		`INSERT IGNORE INTO curation_tag (fk_submission_file_id, name) VALUES `+valuePlaceholder+strings.Repeat(`,`+valuePlaceholder, len(tags)-1),
+		data...)
+	return err
+}
+
+// storeCurationLanguages stores normalized curation language codes of a given submission file into the language lookup table,
+// codes which are not ISO 639-1 are stored as well, flagged as unknown
+func storeCurationLanguages(dbs DBSession, sfid int64, codes []string) error {
+	if len(codes) == 0 {
+		return nil
+	}
+	data := make([]interface{}, 0, len(codes)*3)
+	for _, code := range codes {
+		data = append(data, sfid, code, utils.IsKnownLanguageCode(code))
+	}
+
+	const valuePlaceholder = `(?, ?, ?)`
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(),
+		`INSERT IGNORE INTO curation_language (fk_submission_file_id, code, is_known) VALUES `+valuePlaceholder+strings.Repeat(`,`+valuePlaceholder, len(codes)-1),
+		data...)
+	return err
+}
+
+// DiffCurationMeta returns curation meta fields which differ between two submission files, a file without meta counts as having all fields empty
+func (d *mysqlDAL) DiffCurationMeta(dbs DBSession, oldSFID, newSFID int64) (*types.CurationMetaDiff, error) {
+	oldMeta, err := d.GetCurationMetaBySubmissionFileID(dbs, oldSFID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	newMeta, err := d.GetCurationMetaBySubmissionFileID(dbs, newSFID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return &types.CurationMetaDiff{
+		OldSubmissionFileID: oldSFID,
+		NewSubmissionFileID: newSFID,
+		Fields:              types.DiffCurationMetas(oldMeta, newMeta),
+	}, nil
+}
+
+// GetLanguageDistribution returns the number of submissions per curation language code, based on the newest file of each submission
+func (d *mysqlDAL) GetLanguageDistribution(dbs DBSession) (map[string]int, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT curation_language.code, COUNT(*) FROM submission
+		JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+		JOIN curation_language ON curation_language.fk_submission_file_id = submission_cache.fk_newest_file_id
+		WHERE submission.deleted_at IS NULL
+		GROUP BY curation_language.code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]int)
+	for rows.Next() {
+		var code string
+		var count int
+		if err := rows.Scan(&code, &count); err != nil {
+			return nil, err
+		}
+		result[code] = count
+	}
+
+	return result, nil
+}
+
+// GetDistinctPlatforms returns all platforms used in curation metas, sorted alphabetically
+func (d *mysqlDAL) GetDistinctPlatforms(dbs DBSession) ([]string, error) {
+	return getDistinctCurationMetaValues(dbs, "platform")
+}
+
+// GetDistinctLibraries returns all libraries used in curation metas, sorted alphabetically
+func (d *mysqlDAL) GetDistinctLibraries(dbs DBSession) ([]string, error) {
+	return getDistinctCurationMetaValues(dbs, "library")
+}
+
+// GetDistinctCurationStatuses returns all curation statuses used in curation metas, sorted alphabetically
+func (d *mysqlDAL) GetDistinctCurationStatuses(dbs DBSession) ([]string, error) {
+	return getDistinctCurationMetaValues(dbs, "status")
+}
+
+// getDistinctCurationMetaValues returns distinct non-empty trimmed values of a curation_meta column, the column must not come from user input
+func getDistinctCurationMetaValues(dbs DBSession, column string) ([]string, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT DISTINCT TRIM(`+column+`) AS value FROM curation_meta
+		WHERE TRIM(`+column+`) != ''
+		ORDER BY value`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]string, 0)
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+
+	return result, nil
+}
+
+// curationMetaColumns are the curation_meta columns read by scanCurationMeta, in its order
+const curationMetaColumns = `curation_meta.fk_submission_file_id, submission_file.fk_submission_id, application_path, developer, extreme, game_notes, languages,
+                           launch_command, original_description, play_mode, platform, publisher, release_date, series, source, status,
+                           tags, tag_categories, title, alternate_titles, library, version, curation_notes, mount_parameters,
+                           curation_meta.created_at, curation_meta.updated_at`
+
+// scanCurationMeta scans a row of curationMetaColumns into c, columns selected after them are scanned into extra
+func scanCurationMeta(row interface{ Scan(...interface{}) error }, c *types.CurationMeta, extra ...interface{}) error {
+	var createdAt, updatedAt int64
+	dest := []interface{}{&c.SubmissionFileID, &c.SubmissionID, &c.ApplicationPath, &c.Developer, &c.Extreme, &c.GameNotes, &c.Languages,
+		&c.LaunchCommand, &c.OriginalDescription, &c.PlayMode, &c.Platform, &c.Publisher, &c.ReleaseDate, &c.Series, &c.Source, &c.Status,
+		&c.Tags, &c.TagCategories, &c.Title, &c.AlternateTitles, &c.Library, &c.Version, &c.CurationNotes, &c.MountParameters,
+		&createdAt, &updatedAt}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return err
+	}
+	c.CreatedAt = time.Unix(createdAt, 0)
+	c.UpdatedAt = time.Unix(updatedAt, 0)
+	return nil
+}
+
+// GetCurationMetaBySubmissionFileID returns curation meta for given submission file
+func (d *mysqlDAL) GetCurationMetaBySubmissionFileID(dbs DBSession, sfid int64) (*types.CurationMeta, error) {
+	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT `+curationMetaColumns+`
+		FROM curation_meta JOIN submission_file ON curation_meta.fk_submission_file_id = submission_file.id
+		WHERE fk_submission_file_id=? AND submission_file.deleted_at IS NULL`, sfid)
+
+	c := &types.CurationMeta{}
+	if err := scanCurationMeta(row, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// GetCurationMetasByFileIDs returns curation metas of given submission files keyed by file ID, files without meta are left out
+func (d *mysqlDAL) GetCurationMetasByFileIDs(dbs DBSession, sfids []int64) (map[int64]*types.CurationMeta, error) {
+	result := make(map[int64]*types.CurationMeta, len(sfids))
+	if len(sfids) == 0 {
+		return result, nil
+	}
+
+	args := make([]interface{}, 0, len(sfids))
+	for _, sfid := range sfids {
+		args = append(args, sfid)
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `SELECT `+curationMetaColumns+`
+		FROM curation_meta JOIN submission_file ON curation_meta.fk_submission_file_id = submission_file.id
+		WHERE fk_submission_file_id IN (?`+strings.Repeat(",?", len(sfids)-1)+`) AND submission_file.deleted_at IS NULL`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c := &types.CurationMeta{}
+		if err := scanCurationMeta(rows, c); err != nil {
+			return nil, err
+		}
+		result[c.SubmissionFileID] = c
+	}
+
+	return result, nil
+}
+
+// StoreComment stores curation meta
+func (d *mysqlDAL) StoreComment(dbs DBSession, c *types.Comment) error {
+	_, err := d.storeComment(dbs, c)
+	return err
+}
+
+// storeComment stores a comment and returns its ID
+func (d *mysqlDAL) storeComment(dbs DBSession, c *types.Comment) (int64, error) {
+	var msg *string
+	if c.Message != nil {
+		s := strings.TrimSpace(*c.Message)
+		msg = &s
+	}
+
+	format := c.Format
+	if format == "" {
+		format = constants.CommentFormatPlain
+	}
+	visibility := c.Visibility
+	if visibility == "" {
+		visibility = constants.CommentVisibilityPublic
+	}
+
+	if c.ParentCommentID != nil {
+		var parentSID int64
+		row := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT fk_submission_id FROM comment WHERE id = ?`, *c.ParentCommentID)
+		if err := row.Scan(&parentSID); err != nil {
+			return 0, err
+		}
+		if parentSID != c.SubmissionID {
+			return 0, fmt.Errorf(constants.ErrorParentCommentFromDifferentSubmission)
+		}
+	}
+
+	res, err := d.execWithRetry(dbs, `
+		INSERT INTO comment (fk_user_id, fk_submission_id, message, format, visibility, fk_action_id, created_at, fk_parent_comment_id)
+        VALUES (?, ?, ?, ?, ?, (SELECT id FROM action WHERE name=?), ?, ?)`,
+		c.AuthorID, c.SubmissionID, msg, format, visibility, c.Action, c.CreatedAt.Unix(), c.ParentCommentID)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// CommentAndSetStatus stores a comment and sets review status of its submission, returning ID of the comment.
+// Both happen in the session's transaction, so a failed call rolled back by the caller leaves neither behind.
+// The status is derived from the latest action, so it has to be the one the comment's action leads to, see constants.SubmissionStatusForAction.
+func (d *mysqlDAL) CommentAndSetStatus(dbs DBSession, c *types.Comment, status string) (int64, error) {
+	if derived, ok := constants.SubmissionStatusForAction(c.Action); !ok || derived != status {
+		return 0, fmt.Errorf(constants.ErrorInvalidStatusTransition)
+	}
+
+	cid, err := d.storeComment(dbs, c)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := d.SetSubmissionStatus(dbs, c.SubmissionID, status, c.AuthorID); err != nil {
+		return 0, err
+	}
+
+	return cid, nil
+}
+
+// AssignSubmissions assigns a batch of submissions for testing to a single user, recording the same assign-testing comments a self-assignment does.
+// Testers assigned to a submission before are unassigned, submissions the user already has are left untouched.
+// Assignments are derived from each tester's own comments, so the unassign-testing comments stay on the displaced testers,
+// with a message naming the acting user who reassigned the submission.
+// A missing or deleted submission fails the whole batch with sql.ErrNoRows, so the caller can roll back the session.
+func (d *mysqlDAL) AssignSubmissions(dbs DBSession, sids []int64, assigneeUID, actorUID int64) (*types.BulkAssignmentResult, error) {
+	result := &types.BulkAssignmentResult{}
+
+	unique := make([]int64, 0, len(sids))
+	seen := make(map[int64]bool)
+	for _, sid := range sids {
+		if !seen[sid] {
+			seen[sid] = true
+			unique = append(unique, sid)
+		}
+	}
+	if len(unique) == 0 {
+		return result, nil
+	}
+
+	args := make([]interface{}, 0, len(unique))
+	for _, sid := range unique {
+		args = append(args, sid)
+	}
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT submission.id, submission_cache.active_assigned_testing_ids FROM submission
+		JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+		WHERE submission.id IN (?`+strings.Repeat(",?", len(unique)-1)+`) AND submission.deleted_at IS NULL
+		FOR UPDATE`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	assignees := make(map[int64]*string, len(unique))
+	for rows.Next() {
+		var sid int64
+		var ids *string
+		if err := rows.Scan(&sid, &ids); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		assignees[sid] = ids
+	}
+	rows.Close()
+	if len(assignees) != len(unique) {
+		return nil, sql.ErrNoRows
+	}
+
+	now := time.Now().Unix()
+	unassignMsg := fmt.Sprintf("Unassigned by user %d, the submission was reassigned to user %d", actorUID, assigneeUID)
+	data := make([]interface{}, 0, len(unique)*5)
+	changed := make([]int64, 0, len(unique))
+	for _, sid := range unique {
+		current := make([]int64, 0)
+		if assignees[sid] != nil {
+			for _, id := range strings.Split(*assignees[sid], ",") {
+				uid, err := strconv.ParseInt(id, 10, 64)
+				if err != nil {
+					return nil, err
+				}
+				current = append(current, uid)
+			}
+		}
+
+		alreadyAssigned := false
+		for _, uid := range current {
+			if uid == assigneeUID {
+				alreadyAssigned = true
+				break
+			}
+		}
+		if alreadyAssigned {
+			result.AlreadyAssigned++
+			continue
+		}
+
+		if len(current) > 0 {
+			result.Reassigned++
+			for _, uid := range current {
+				data = append(data, uid, sid, unassignMsg, constants.ActionUnassignTesting, now)
+			}
+		} else {
+			result.NewlyAssigned++
+		}
+		data = append(data, assigneeUID, sid, nil, constants.ActionAssignTesting, now)
+		changed = append(changed, sid)
+	}
+	if len(changed) == 0 {
+		return result, nil
+	}
+
+	const valuePlaceholder = `(?, ?, ?, (SELECT id FROM action WHERE name=?), ?)`
+	_, err = d.execWithRetry(dbs,
+		`INSERT INTO comment (fk_user_id, fk_submission_id, message, fk_action_id, created_at) VALUES `+valuePlaceholder+strings.Repeat(`,`+valuePlaceholder, len(data)/5-1),
+		data...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sid := range changed {
+		if err := d.RecomputeSubmissionDerivedFields(dbs, sid); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// GetExtendedCommentsBySubmissionID returns comments with author data for a given submission and visibility, empty visibility means all comments
+func (d *mysqlDAL) GetExtendedCommentsBySubmissionID(dbs DBSession, sid int64, visibility string) ([]*types.ExtendedComment, error) {
+	return d.GetExtendedCommentsBySubmissionIDFiltered(dbs, sid, nil, visibility)
+}
+
+// GetExtendedCommentsBySubmissionIDFiltered returns comments with author data for a given submission, restricted to given actions and visibility.
+// Empty actions mean all actions, empty visibility means all comments.
+func (d *mysqlDAL) GetExtendedCommentsBySubmissionIDFiltered(dbs DBSession, sid int64, actions []string, visibility string) ([]*types.ExtendedComment, error) {
+	data := []interface{}{sid}
+	visibilityFilter := ""
+	if visibility != "" {
+		visibilityFilter = `AND comment.visibility = ?`
+		data = append(data, visibility)
+	}
+	actionFilter := ""
+	if len(actions) > 0 {
+		actionFilter = `AND comment.fk_action_id IN (SELECT id FROM action WHERE name IN (?` + strings.Repeat(",?", len(actions)-1) + `))`
+		for _, action := range actions {
+			data = append(data, action)
+		}
+	}
+
+	query := `
+		SELECT comment.id, discord_user.id, COALESCE(display_name, username), avatar, message, format, visibility, (SELECT name FROM action WHERE id=comment.fk_action_id) as action, created_at, pinned, fk_parent_comment_id
+		FROM comment
+		JOIN discord_user ON discord_user.id = fk_user_id
+		WHERE fk_submission_id=?
+		AND comment.deleted_at IS NULL
+		` + visibilityFilter + `
+		` + actionFilter + `
+		ORDER BY pinned DESC, created_at;`
+
+	var rows *sql.Rows
+	var err error
+	if len(actions) == 0 {
+		// variants without action filter are static and hot enough to keep prepared
+		var stmt *timedStmt
+		stmt, err = d.prepared(dbs, query)
+		if err != nil {
+			return nil, err
+		}
+		rows, err = stmt.QueryContext(dbs.Ctx(), data...)
+	} else {
+		rows, err = dbs.Tx().QueryContext(dbs.Ctx(), query, data...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*types.ExtendedComment, 0)
+
+	var createdAt int64
+	var avatar string
+
+	for rows.Next() {
+
+		ec := &types.ExtendedComment{SubmissionID: sid}
+		if err := rows.Scan(&ec.CommentID, &ec.AuthorID, &ec.Username, &avatar, &ec.Message, &ec.Format, &ec.Visibility, &ec.Action, &createdAt, &ec.Pinned, &ec.ParentCommentID); err != nil {
+			return nil, err
+		}
+		ec.CreatedAt = time.Unix(createdAt, 0)
+		ec.AvatarURL = utils.FormatAvatarURL(ec.AuthorID, avatar)
+		result = append(result, ec)
 	}
 
 	return result, nil
 }
 
-// GetExtendedSubmissionFilesBySubmissionID returns all extended submission files for a given submission
-func (d *mysqlDAL) GetExtendedSubmissionFilesBySubmissionID(dbs DBSession, sid int64) ([]*types.ExtendedSubmissionFile, error) {
+// GetCommentByID returns a comment, deleted comments are not found
+func (d *mysqlDAL) GetCommentByID(dbs DBSession, cid int64) (*types.Comment, error) {
+	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `
+		SELECT fk_user_id, fk_submission_id, message, format, visibility, (SELECT name FROM action WHERE id=comment.fk_action_id), created_at
+		FROM comment
+		WHERE id = ? AND deleted_at IS NULL`,
+		cid)
+
+	c := &types.Comment{}
+	var createdAt int64
+	if err := row.Scan(&c.AuthorID, &c.SubmissionID, &c.Message, &c.Format, &c.Visibility, &c.Action, &createdAt); err != nil {
+		return nil, err
+	}
+	c.CreatedAt = time.Unix(createdAt, 0)
+
+	return c, nil
+}
+
+// GetCommentTreeBySubmissionID returns comments of a given submission nested under the comments they reply to
+func (d *mysqlDAL) GetCommentTreeBySubmissionID(dbs DBSession, sid int64) ([]*types.CommentTreeNode, error) {
+	comments, err := d.GetExtendedCommentsBySubmissionID(dbs, sid, "")
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[int64]*types.CommentTreeNode, len(comments))
+	for _, c := range comments {
+		nodes[c.CommentID] = &types.CommentTreeNode{Comment: c, Replies: []*types.CommentTreeNode{}}
+	}
+
+	result := make([]*types.CommentTreeNode, 0)
+	for _, c := range comments {
+		node := nodes[c.CommentID]
+		if c.ParentCommentID != nil {
+			// replies to deleted comments are kept at the top level
+			if parent, ok := nodes[*c.ParentCommentID]; ok {
+				parent.Replies = append(parent.Replies, node)
+				continue
+			}
+		}
+		result = append(result, node)
+	}
+
+	return result, nil
+}
+
+// GetSubmissionActionTimeline returns state-changing actions of a given submission in chronological order, plain comments are left out
+func (d *mysqlDAL) GetSubmissionActionTimeline(dbs DBSession, sid int64) ([]*types.ActionEvent, error) {
 	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
-		SELECT submission_file.id, fk_user_id, username, avatar, 
-		       original_filename, current_filename, size, created_at, md5sum, sha256sum 
-		FROM submission_file 
-		LEFT JOIN discord_user ON fk_user_id=discord_user.id
-		WHERE fk_submission_id=?
-		AND submission_file.deleted_at IS NULL
-		ORDER BY created_at DESC`, sid)
+		SELECT comment.id, action.name, discord_user.id, COALESCE(discord_user.display_name, discord_user.username), comment.created_at
+		FROM comment
+		JOIN action ON action.id = comment.fk_action_id
+		JOIN discord_user ON discord_user.id = comment.fk_user_id
+		WHERE comment.fk_submission_id = ?
+		AND comment.deleted_at IS NULL
+		AND action.name != ?
+		ORDER BY comment.created_at, comment.id`,
+		sid, constants.ActionComment)
 	if err != nil {
 		return nil, err
 	}
-	var result = make([]*types.ExtendedSubmissionFile, 0)
-	var avatar string
-	var uploadedAt int64
+	defer rows.Close()
+
+	result := make([]*types.ActionEvent, 0)
+
+	var createdAt int64
+
 	for rows.Next() {
-		sf := &types.ExtendedSubmissionFile{SubmissionID: sid}
-		err := rows.Scan(&sf.FileID, &sf.SubmitterID, &sf.SubmitterUsername, &avatar,
-			&sf.OriginalFilename, &sf.CurrentFilename, &sf.Size, &uploadedAt, &sf.MD5Sum, &sf.SHA256Sum)
-		if err != nil {
+		ae := &types.ActionEvent{}
+		if err := rows.Scan(&ae.CommentID, &ae.Action, &ae.ActorID, &ae.Username, &createdAt); err != nil {
 			return nil, err
 		}
-		sf.SubmitterAvatarURL = utils.FormatAvatarURL(sf.SubmitterID, avatar)
-		sf.UploadedAt = time.Unix(uploadedAt, 0)
-		result = append(result, sf)
+		ae.CreatedAt = time.Unix(createdAt, 0)
+		ae.IsBot = ae.ActorID == constants.ValidatorID
+		result = append(result, ae)
 	}
+
 	return result, nil
 }
 
-// StoreCurationMeta stores curation meta
-func (d *mysqlDAL) StoreCurationMeta(dbs DBSession, cm *types.CurationMeta) error {
-	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `INSERT INTO curation_meta (fk_submission_file_id, application_path, developer, extreme, game_notes, languages,
-                           launch_command, original_description, play_mode, platform, publisher, release_date, series, source, status,
-                           tags, tag_categories, title, alternate_titles, library, version, curation_notes, mount_parameters) 
-                           VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		cm.SubmissionFileID, cm.ApplicationPath, cm.Developer, cm.Extreme, cm.GameNotes, cm.Languages,
-		cm.LaunchCommand, cm.OriginalDescription, cm.PlayMode, cm.Platform, cm.Publisher, cm.ReleaseDate, cm.Series, cm.Source, cm.Status,
-		cm.Tags, cm.TagCategories, cm.Title, cm.AlternateTitles, cm.Library, cm.Version, cm.CurationNotes, cm.MountParameters)
-	return err
+// GetExtendedCommentByID returns a single comment with author data
+func (d *mysqlDAL) GetExtendedCommentByID(dbs DBSession, cid int64) (*types.ExtendedComment, error) {
+	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `
+		SELECT comment.id, discord_user.id, COALESCE(display_name, username), avatar, fk_submission_id, message, format, visibility, (SELECT name FROM action WHERE id=comment.fk_action_id) as action, created_at, pinned, fk_parent_comment_id
+		FROM comment
+		JOIN discord_user ON discord_user.id = fk_user_id
+		WHERE comment.id=?
+		AND comment.deleted_at IS NULL`,
+		cid)
+
+	ec := &types.ExtendedComment{}
+	var createdAt int64
+	var avatar string
+	if err := row.Scan(&ec.CommentID, &ec.AuthorID, &ec.Username, &avatar, &ec.SubmissionID, &ec.Message, &ec.Format, &ec.Visibility, &ec.Action, &createdAt, &ec.Pinned, &ec.ParentCommentID); err != nil {
+		return nil, err
+	}
+	ec.CreatedAt = time.Unix(createdAt, 0)
+	ec.AvatarURL = utils.FormatAvatarURL(ec.AuthorID, avatar)
+
+	return ec, nil
 }
 
-// GetCurationMetaBySubmissionFileID returns curation meta for given submission file
-func (d *mysqlDAL) GetCurationMetaBySubmissionFileID(dbs DBSession, sfid int64) (*types.CurationMeta, error) {
-	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT submission_file.fk_submission_id, application_path, developer, extreme, game_notes, languages,
-                           launch_command, original_description, play_mode, platform, publisher, release_date, series, source, status,
-                           tags, tag_categories, title, alternate_titles, library, version, curation_notes, mount_parameters 
-		FROM curation_meta JOIN submission_file ON curation_meta.fk_submission_file_id = submission_file.id
-		WHERE fk_submission_file_id=? AND submission_file.deleted_at IS NULL`, sfid)
+// SearchComments returns the most recent human comments containing given text, with messages shortened to a snippet around the match.
+// Limit is capped to a sane maximum.
+func (d *mysqlDAL) SearchComments(dbs DBSession, query string, limit int) ([]*types.ExtendedComment, error) {
+	const maxLimit = 100
+	if limit < 1 || limit > maxLimit {
+		limit = maxLimit
+	}
 
-	c := &types.CurationMeta{SubmissionFileID: sfid}
-	err := row.Scan(&c.SubmissionID, &c.ApplicationPath, &c.Developer, &c.Extreme, &c.GameNotes, &c.Languages,
-		&c.LaunchCommand, &c.OriginalDescription, &c.PlayMode, &c.Platform, &c.Publisher, &c.ReleaseDate, &c.Series, &c.Source, &c.Status,
-		&c.Tags, &c.TagCategories, &c.Title, &c.AlternateTitles, &c.Library, &c.Version, &c.CurationNotes, &c.MountParameters)
+	query = strings.TrimSpace(query)
+	if len(query) == 0 {
+		return []*types.ExtendedComment{}, nil
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT comment.id, discord_user.id, COALESCE(display_name, username), avatar, fk_submission_id, message, format, visibility, (SELECT name FROM action WHERE id=comment.fk_action_id) as action, created_at, pinned, fk_parent_comment_id
+		FROM comment
+		JOIN discord_user ON discord_user.id = fk_user_id
+		WHERE comment.deleted_at IS NULL
+		AND comment.message IS NOT NULL
+		AND comment.fk_user_id != ?
+		AND comment.message LIKE ?
+		ORDER BY created_at DESC, comment.id DESC
+		LIMIT ?`,
+		constants.ValidatorID, utils.FormatLike(utils.EscapeLike(query)), limit)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return c, nil
+	result := make([]*types.ExtendedComment, 0)
+
+	var createdAt int64
+	var avatar string
+
+	for rows.Next() {
+		ec := &types.ExtendedComment{}
+		if err := rows.Scan(&ec.CommentID, &ec.AuthorID, &ec.Username, &avatar, &ec.SubmissionID, &ec.Message, &ec.Format, &ec.Visibility, &ec.Action, &createdAt, &ec.Pinned, &ec.ParentCommentID); err != nil {
+			return nil, err
+		}
+		ec.CreatedAt = time.Unix(createdAt, 0)
+		ec.AvatarURL = utils.FormatAvatarURL(ec.AuthorID, avatar)
+		if ec.Message != nil {
+			snippet := commentSnippet(*ec.Message, query)
+			ec.Message = &snippet
+		}
+		result = append(result, ec)
+	}
+
+	return result, nil
 }
 
-// StoreComment stores curation meta
-func (d *mysqlDAL) StoreComment(dbs DBSession, c *types.Comment) error {
-	var msg *string
-	if c.Message != nil {
-		s := strings.TrimSpace(*c.Message)
-		msg = &s
+// GetRecentComments returns a page of comments across all submissions, newest first.
+// Limit is capped to a sane maximum.
+func (d *mysqlDAL) GetRecentComments(dbs DBSession, limit, offset int) ([]*types.ExtendedComment, error) {
+	const maxLimit = 100
+	if limit < 1 || limit > maxLimit {
+		limit = maxLimit
 	}
-	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
-		INSERT INTO comment (fk_user_id, fk_submission_id, message, fk_action_id, created_at) 
-        VALUES (?, ?, ?, (SELECT id FROM action WHERE name=?), ?)`,
-		c.AuthorID, c.SubmissionID, msg, c.Action, c.CreatedAt.Unix())
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT comment.id, discord_user.id, COALESCE(display_name, username), avatar, comment.fk_submission_id, message, format, visibility, (SELECT name FROM action WHERE id=comment.fk_action_id) as action, comment.created_at, pinned, fk_parent_comment_id, meta.title
+		FROM comment
+		JOIN discord_user ON discord_user.id = comment.fk_user_id
+		LEFT JOIN submission_cache ON submission_cache.fk_submission_id = comment.fk_submission_id
+		LEFT JOIN curation_meta meta ON meta.fk_submission_file_id = submission_cache.fk_newest_file_id
+		WHERE comment.deleted_at IS NULL
+		ORDER BY comment.created_at DESC, comment.id DESC
+		LIMIT ? OFFSET ?`,
+		limit, offset)
 	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*types.ExtendedComment, 0, limit)
+
+	var createdAt int64
+	var avatar string
+
+	for rows.Next() {
+		ec := &types.ExtendedComment{}
+		if err := rows.Scan(&ec.CommentID, &ec.AuthorID, &ec.Username, &avatar, &ec.SubmissionID, &ec.Message, &ec.Format, &ec.Visibility, &ec.Action, &createdAt, &ec.Pinned, &ec.ParentCommentID, &ec.SubmissionTitle); err != nil {
+			return nil, err
+		}
+		ec.CreatedAt = time.Unix(createdAt, 0)
+		ec.AvatarURL = utils.FormatAvatarURL(ec.AuthorID, avatar)
+		result = append(result, ec)
+	}
+
+	return result, nil
+}
+
+// SaveReviewerNote stores the personal note of a user on a submission, replacing their previous note, empty body deletes it
+func (d *mysqlDAL) SaveReviewerNote(dbs DBSession, sid, uid int64, body string) error {
+	body = strings.TrimSpace(body)
+	if len(body) == 0 {
+		_, err := dbs.Tx().ExecContext(dbs.Ctx(), `DELETE FROM reviewer_note WHERE fk_submission_id = ? AND fk_user_id = ?`, sid, uid)
 		return err
 	}
 
-	return nil
+	now := time.Now().Unix()
+	_, err := d.execWithRetry(dbs, `
+		INSERT INTO reviewer_note (fk_submission_id, fk_user_id, body, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE body = ?, updated_at = ?`,
+		sid, uid, body, now, now,
+		body, now)
+	return err
 }
 
-// GetExtendedCommentsBySubmissionID returns all comments with author data for a given submission
-func (d *mysqlDAL) GetExtendedCommentsBySubmissionID(dbs DBSession, sid int64) ([]*types.ExtendedComment, error) {
+// GetReviewerNotes returns reviewer notes on a submission, only those of a given author if authorID is not nil.
+// Notes are personal, callers decide who may read whose notes.
+func (d *mysqlDAL) GetReviewerNotes(dbs DBSession, sid int64, authorID *int64) ([]*types.ReviewerNote, error) {
+	authorFilter := ""
+	args := []interface{}{sid}
+	if authorID != nil {
+		authorFilter = `AND reviewer_note.fk_user_id = ?`
+		args = append(args, *authorID)
+	}
+
 	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
-		SELECT comment.id, discord_user.id, username, avatar, message, (SELECT name FROM action WHERE id=comment.fk_action_id) as action, created_at 
-		FROM comment 
-		JOIN discord_user ON discord_user.id = fk_user_id
-		WHERE fk_submission_id=? 
-		AND comment.deleted_at IS NULL
-		ORDER BY created_at;`, sid)
+		SELECT reviewer_note.id, reviewer_note.fk_submission_id, reviewer_note.fk_user_id, COALESCE(display_name, username), reviewer_note.body, reviewer_note.created_at, reviewer_note.updated_at
+		FROM reviewer_note
+		JOIN discord_user ON discord_user.id = reviewer_note.fk_user_id
+		WHERE reviewer_note.fk_submission_id = ? `+authorFilter+`
+		ORDER BY reviewer_note.updated_at DESC, reviewer_note.id DESC`,
+		args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	result := make([]*types.ExtendedComment, 0)
+	result := make([]*types.ReviewerNote, 0)
 
 	var createdAt int64
-	var avatar string
+	var updatedAt int64
 
 	for rows.Next() {
+		rn := &types.ReviewerNote{}
+		if err := rows.Scan(&rn.ID, &rn.SubmissionID, &rn.AuthorID, &rn.Username, &rn.Body, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		rn.CreatedAt = time.Unix(createdAt, 0)
+		rn.UpdatedAt = time.Unix(updatedAt, 0)
+		result = append(result, rn)
+	}
 
-		ec := &types.ExtendedComment{SubmissionID: sid}
-		if err := rows.Scan(&ec.CommentID, &ec.AuthorID, &ec.Username, &avatar, &ec.Message, &ec.Action, &createdAt); err != nil {
+	return result, nil
+}
+
+// GetCommentsForDigest returns comments posted since a given time on submissions each user watches, keyed by the watching user, oldest first.
+// Submitters watch their own submissions without subscribing, users do not get their own comments
+// and internal comments go only to watchers with a staff role.
+func (d *mysqlDAL) GetCommentsForDigest(dbs DBSession, since time.Time) (map[int64][]*types.ExtendedComment, error) {
+	staffRoles := constants.StaffRoles()
+	args := []interface{}{since.Unix(), since.Unix(), since.Unix(), constants.CommentVisibilityPublic}
+	for _, role := range staffRoles {
+		args = append(args, role)
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT watcher.uid, comment.id, discord_user.id, COALESCE(display_name, username), avatar, comment.fk_submission_id, message, format, visibility, (SELECT name FROM action WHERE id=comment.fk_action_id) as action, comment.created_at, pinned, fk_parent_comment_id, meta.title
+		FROM (
+			SELECT sns.fk_user_id AS uid, sns.fk_submission_id AS sid FROM submission_notification_subscription AS sns
+			JOIN submission ON submission.id = sns.fk_submission_id
+			WHERE submission.deleted_at IS NULL
+			AND sns.fk_submission_id IN (SELECT fk_submission_id FROM comment WHERE created_at >= ?)
+			UNION
+			SELECT COALESCE(submission.fk_owner_id, oldest_file.fk_user_id), submission.id FROM submission
+			JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+			JOIN submission_file AS oldest_file ON oldest_file.id = submission_cache.fk_oldest_file_id
+			WHERE submission.deleted_at IS NULL
+			AND submission.id IN (SELECT fk_submission_id FROM comment WHERE created_at >= ?)
+		) AS watcher
+		JOIN comment ON comment.fk_submission_id = watcher.sid
+		JOIN discord_user ON discord_user.id = comment.fk_user_id
+		LEFT JOIN submission_cache ON submission_cache.fk_submission_id = comment.fk_submission_id
+		LEFT JOIN curation_meta meta ON meta.fk_submission_file_id = submission_cache.fk_newest_file_id
+		WHERE comment.created_at >= ? AND comment.deleted_at IS NULL AND comment.fk_user_id != watcher.uid
+		AND (comment.visibility = ? OR EXISTS (
+			SELECT 1 FROM discord_user_role
+			JOIN discord_role ON discord_role.id = discord_user_role.fk_rid
+			WHERE discord_user_role.fk_uid = watcher.uid
+			AND discord_role.name IN (?`+strings.Repeat(",?", len(staffRoles)-1)+`)))
+		ORDER BY watcher.uid, comment.created_at, comment.id`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64][]*types.ExtendedComment)
+
+	var watcherID int64
+	var createdAt int64
+	var avatar string
+
+	for rows.Next() {
+		ec := &types.ExtendedComment{}
+		if err := rows.Scan(&watcherID, &ec.CommentID, &ec.AuthorID, &ec.Username, &avatar, &ec.SubmissionID, &ec.Message, &ec.Format, &ec.Visibility, &ec.Action, &createdAt, &ec.Pinned, &ec.ParentCommentID, &ec.SubmissionTitle); err != nil {
 			return nil, err
 		}
 		ec.CreatedAt = time.Unix(createdAt, 0)
 		ec.AvatarURL = utils.FormatAvatarURL(ec.AuthorID, avatar)
-		result = append(result, ec)
+		result[watcherID] = append(result[watcherID], ec)
 	}
 
 	return result, nil
 }
 
-// GetCommentByID returns a comment
-func (d *mysqlDAL) GetCommentByID(dbs DBSession, cid int64) (*types.Comment, error) {
-	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `
-		SELECT fk_user_id, fk_submission_id, message, (SELECT name FROM action WHERE id=comment.fk_action_id), created_at
-		FROM comment
+// GetCommentRateByUser returns the number of comments a user posted within a trailing window, deleted comments included.
+// The validator bot posts in bursts by design and always gets zero.
+func (d *mysqlDAL) GetCommentRateByUser(dbs DBSession, uid int64, window time.Duration) (int, error) {
+	if uid == constants.ValidatorID {
+		return 0, nil
+	}
+
+	var count int
+	err := dbs.Tx().QueryRowContext(dbs.Ctx(), `
+		SELECT COUNT(*) FROM comment
+		WHERE fk_user_id = ? AND created_at >= ?`,
+		uid, time.Now().Add(-window).Unix()).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetUsersExceedingCommentRate returns users who posted at least threshold comments within a trailing window, deleted comments included, busiest first.
+// The validator bot is excluded.
+func (d *mysqlDAL) GetUsersExceedingCommentRate(dbs DBSession, window time.Duration, threshold int) ([]*types.CommentRate, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT discord_user.id, COALESCE(discord_user.display_name, discord_user.username), COUNT(*) AS comment_count FROM comment
+		JOIN discord_user ON discord_user.id = comment.fk_user_id
+		WHERE comment.created_at >= ? AND comment.fk_user_id != ?
+		GROUP BY discord_user.id
+		HAVING comment_count >= ?
+		ORDER BY comment_count DESC, discord_user.id`,
+		time.Now().Add(-window).Unix(), constants.ValidatorID, threshold)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*types.CommentRate, 0)
+	for rows.Next() {
+		cr := &types.CommentRate{}
+		if err := rows.Scan(&cr.UserID, &cr.Username, &cr.Count); err != nil {
+			return nil, err
+		}
+		result = append(result, cr)
+	}
+
+	return result, nil
+}
+
+// commentSnippet cuts a message down to the surroundings of the first case-insensitive occurrence of query
+func commentSnippet(message, query string) string {
+	const surroundingRunes = 80
+
+	// rune-wise lowercasing keeps rune offsets of the lowered message valid for the original one
+	lowered := strings.Map(unicode.ToLower, message)
+	m := []rune(message)
+	start := 0
+	if i := strings.Index(lowered, strings.Map(unicode.ToLower, query)); i >= 0 {
+		start = utf8.RuneCountInString(lowered[:i]) - surroundingRunes
+		if start < 0 {
+			start = 0
+		}
+	}
+	end := start + 2*surroundingRunes + utf8.RuneCountInString(query)
+	if end > len(m) {
+		end = len(m)
+	}
+
+	snippet := string(m[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(m) {
+		snippet = snippet + "…"
+	}
+	return snippet
+}
+
+// PinComment marks comment as pinned by given user
+func (d *mysqlDAL) PinComment(dbs DBSession, cid, actorUID int64) error {
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
+		UPDATE comment SET pinned = TRUE, fk_pinned_by_id = ?
+		WHERE id = ?`,
+		actorUID, cid)
+	return err
+}
+
+// UnpinComment clears the pinned flag of a comment together with who pinned it
+func (d *mysqlDAL) UnpinComment(dbs DBSession, cid int64) error {
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
+		UPDATE comment SET pinned = FALSE, fk_pinned_by_id = NULL
 		WHERE id = ?`,
 		cid)
+	return err
+}
 
-	c := &types.Comment{}
-	var createdAt int64
-	if err := row.Scan(&c.AuthorID, &c.SubmissionID, &c.Message, &c.Action, &createdAt); err != nil {
-		return nil, err
+// AddCommentReaction adds a reaction of a user to a comment, adding the same reaction again does nothing
+func (d *mysqlDAL) AddCommentReaction(dbs DBSession, cid, uid int64, reaction string) error {
+	if !isAllowedCommentReaction(reaction) {
+		return fmt.Errorf(constants.ErrorInvalidCommentReaction)
+	}
+
+	_, err := d.execWithRetry(dbs, `
+		INSERT IGNORE INTO comment_reaction (fk_comment_id, fk_user_id, reaction, created_at)
+		VALUES (?, ?, ?, UNIX_TIMESTAMP())`,
+		cid, uid, reaction)
+	return err
+}
+
+// RemoveCommentReaction removes a reaction of a user from a comment
+func (d *mysqlDAL) RemoveCommentReaction(dbs DBSession, cid, uid int64, reaction string) error {
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
+		DELETE FROM comment_reaction
+		WHERE fk_comment_id = ? AND fk_user_id = ? AND reaction = ?`,
+		cid, uid, reaction)
+	return err
+}
+
+func isAllowedCommentReaction(reaction string) bool {
+	for _, allowed := range constants.GetAllowedCommentReactions() {
+		if reaction == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// PopulateCommentReactions fills reaction counts and reactions of a given viewer for all given comments in a single query
+func (d *mysqlDAL) PopulateCommentReactions(dbs DBSession, comments []*types.ExtendedComment, viewerUID int64) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*types.ExtendedComment, len(comments))
+	data := []interface{}{viewerUID}
+	for _, c := range comments {
+		c.Reactions = make(map[string]int)
+		c.ViewerReactions = []string{}
+		byID[c.CommentID] = c
+		data = append(data, c.CommentID)
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT fk_comment_id, reaction, COUNT(*), SUM(fk_user_id = ?) > 0
+		FROM comment_reaction
+		WHERE fk_comment_id IN (?`+strings.Repeat(",?", len(comments)-1)+`)
+		GROUP BY fk_comment_id, reaction
+		ORDER BY fk_comment_id, reaction`,
+		data...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid int64
+		var reaction string
+		var count int
+		var byViewer bool
+		if err := rows.Scan(&cid, &reaction, &count, &byViewer); err != nil {
+			return err
+		}
+		c, ok := byID[cid]
+		if !ok {
+			continue
+		}
+		c.Reactions[reaction] = count
+		if byViewer {
+			c.ViewerReactions = append(c.ViewerReactions, reaction)
+		}
 	}
-	c.CreatedAt = time.Unix(createdAt, 0)
 
-	return c, nil
+	return nil
 }
 
 // SoftDeleteSubmissionFile marks submission file as deleted
@@ -448,6 +2222,144 @@ func (d *mysqlDAL) SoftDeleteSubmissionFile(dbs DBSession, sfid int64, deleteRea
 	return nil
 }
 
+// DeleteSubmissionFile permanently deletes submission file with its curation meta, tags and images, returning size of the deleted file.
+// Deleting the last file of a submission must be forced. Files on disk are left for the caller to remove.
+func (d *mysqlDAL) DeleteSubmissionFile(dbs DBSession, sfid int64, force bool) (int64, error) {
+	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `
+		SELECT fk_submission_id, size FROM submission_file
+		WHERE id = ?`,
+		sfid)
+
+	var sid int64
+	var size int64
+	if err := row.Scan(&sid, &size); err != nil {
+		return 0, err
+	}
+
+	if !force {
+		row = dbs.Tx().QueryRowContext(dbs.Ctx(), `
+			SELECT COUNT(*) FROM submission_file
+			WHERE fk_submission_id = ? AND id != ?
+			AND deleted_at IS NULL`,
+			sid, sfid)
+
+		var otherCount int64
+		if err := row.Scan(&otherCount); err != nil {
+			return 0, err
+		}
+		if otherCount == 0 {
+			return 0, fmt.Errorf(constants.ErrorCannotDeleteLastSubmissionFile)
+		}
+	}
+
+	// the cache is recomputed below, drop its references first so the file row can go
+	queries := []string{
+		`UPDATE submission_cache SET fk_newest_file_id = NULL WHERE fk_newest_file_id = ?`,
+		`UPDATE submission_cache SET fk_oldest_file_id = NULL WHERE fk_oldest_file_id = ?`,
+		`DELETE FROM curation_tag WHERE fk_submission_file_id = ?`,
+		`DELETE FROM curation_language WHERE fk_submission_file_id = ?`,
+		`DELETE FROM file_download WHERE fk_submission_file_id = ?`,
+		`DELETE FROM curation_image WHERE fk_submission_file_id = ?`,
+		`DELETE FROM curation_meta WHERE fk_submission_file_id = ?`,
+		`DELETE FROM submission_file WHERE id = ?`,
+	}
+	for _, q := range queries {
+		if _, err := dbs.Tx().ExecContext(dbs.Ctx(), q, sfid); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := d.UpdateSubmissionCacheTable(dbs, sid); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// MergeSubmissions moves files, comments, subscriptions, reviewer notes, status history and submission meta of a duplicate submission
+// to the one which is kept, then soft-deletes the duplicate and points it to the kept one, see constants.SubmissionMetaKeyMergedInto.
+// Where both submissions have a subscription, reviewer note or meta key of the same user or key, the kept submission's one wins.
+// Comments keep their creation time, so the combined thread stays in chronological order, and the kept submission's status
+// is derived from it again, whatever the merged comments lead to.
+// Returns sql.ErrNoRows if either submission does not exist or is deleted.
+func (d *mysqlDAL) MergeSubmissions(dbs DBSession, keepSID, mergeSID int64) (*types.SubmissionMerge, error) {
+	if keepSID == mergeSID {
+		return nil, fmt.Errorf(constants.ErrorCannotMergeSubmissionIntoItself)
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT id FROM submission
+		WHERE id IN (?, ?) AND deleted_at IS NULL
+		ORDER BY id
+		FOR UPDATE`,
+		keepSID, mergeSID)
+	if err != nil {
+		return nil, err
+	}
+	found := 0
+	for rows.Next() {
+		found++
+	}
+	rows.Close()
+	if found != 2 {
+		return nil, sql.ErrNoRows
+	}
+
+	// drop subscriptions, notes and meta the kept submission already has, move the rest
+	duplicates := []string{
+		`DELETE merged_sns FROM submission_notification_subscription AS merged_sns
+		JOIN submission_notification_subscription AS kept_sns ON kept_sns.fk_user_id = merged_sns.fk_user_id AND kept_sns.fk_submission_id = ?
+		WHERE merged_sns.fk_submission_id = ?`,
+		`DELETE merged_note FROM reviewer_note AS merged_note
+		JOIN reviewer_note AS kept_note ON kept_note.fk_user_id = merged_note.fk_user_id AND kept_note.fk_submission_id = ?
+		WHERE merged_note.fk_submission_id = ?`,
+		`DELETE merged_meta FROM submission_meta AS merged_meta
+		JOIN submission_meta AS kept_meta ON kept_meta.meta_key = merged_meta.meta_key AND kept_meta.fk_submission_id = ?
+		WHERE merged_meta.fk_submission_id = ?`,
+	}
+	for _, query := range duplicates {
+		if _, err := dbs.Tx().ExecContext(dbs.Ctx(), query, keepSID, mergeSID); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &types.SubmissionMerge{}
+	moves := []struct {
+		query string
+		count *int64
+	}{
+		{`UPDATE submission_file SET fk_submission_id = ? WHERE fk_submission_id = ?`, &result.FilesMoved},
+		{`UPDATE comment SET fk_submission_id = ? WHERE fk_submission_id = ?`, &result.CommentsMoved},
+		{`UPDATE submission_notification_subscription SET fk_submission_id = ? WHERE fk_submission_id = ?`, &result.SubscriptionsMoved},
+		{`UPDATE reviewer_note SET fk_submission_id = ? WHERE fk_submission_id = ?`, &result.NotesMoved},
+		{`UPDATE status_transition SET fk_submission_id = ? WHERE fk_submission_id = ?`, &result.StatusTransitionsMoved},
+		{`UPDATE submission_meta SET fk_submission_id = ? WHERE fk_submission_id = ?`, &result.MetaMoved},
+	}
+	for _, m := range moves {
+		res, err := dbs.Tx().ExecContext(dbs.Ctx(), m.query, keepSID, mergeSID)
+		if err != nil {
+			return nil, err
+		}
+		if *m.count, err = res.RowsAffected(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := d.SoftDeleteSubmission(dbs, mergeSID, fmt.Sprintf("merged into submission %d", keepSID)); err != nil {
+		return nil, err
+	}
+	if err := d.SetSubmissionMeta(dbs, mergeSID, constants.SubmissionMetaKeyMergedInto, strconv.FormatInt(keepSID, 10)); err != nil {
+		return nil, err
+	}
+	// the merged comments can lead to a status change which would not be allowed when done by hand,
+	// e.g. changes requested on the merged submission after the kept one was accepted, the recompute records it anyway
+	if err := d.RecomputeSubmissionDerivedFields(dbs, keepSID); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // SoftDeleteSubmission marks submission and its files as deleted
 func (d *mysqlDAL) SoftDeleteSubmission(dbs DBSession, sid int64, deleteReason string) error {
 	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
@@ -482,7 +2394,7 @@ func (d *mysqlDAL) SoftDeleteSubmission(dbs DBSession, sid int64, deleteReason s
 	return nil
 }
 
-// SoftDeleteComment marks comment as deleted
+// SoftDeleteComment marks comment as deleted and derives the review status of its submission again
 func (d *mysqlDAL) SoftDeleteComment(dbs DBSession, cid int64, deleteReason string) error {
 	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
 		UPDATE comment SET deleted_at = UNIX_TIMESTAMP(), deleted_reason = ?
@@ -503,7 +2415,8 @@ func (d *mysqlDAL) SoftDeleteComment(dbs DBSession, cid int64, deleteReason stri
 		return err
 	}
 
-	err = d.UpdateSubmissionCacheTable(dbs, sid)
+	// the deleted comment may have been the one defining the status
+	err = d.RecomputeSubmissionDerivedFields(dbs, sid)
 	if err != nil {
 		return err
 	}
@@ -595,6 +2508,63 @@ func (d *mysqlDAL) IsUserSubscribedToSubmission(dbs DBSession, uid, sid int64) (
 	return count > 0, nil
 }
 
+// GetSubmissionSubscribers returns IDs of users subscribed to a submission
+func (d *mysqlDAL) GetSubmissionSubscribers(dbs DBSession, sid int64) ([]int64, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT DISTINCT fk_user_id FROM submission_notification_subscription
+		WHERE fk_submission_id = ?
+		ORDER BY fk_user_id`,
+		sid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]int64, 0)
+	var uid int64
+
+	for rows.Next() {
+		if err := rows.Scan(&uid); err != nil {
+			return nil, err
+		}
+		result = append(result, uid)
+	}
+
+	return result, nil
+}
+
+// GetSubscribedSubmissions returns submissions a user is subscribed to, most recently subscribed first
+func (d *mysqlDAL) GetSubscribedSubmissions(dbs DBSession, uid int64) ([]*types.ExtendedSubmission, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT sns.fk_submission_id FROM submission_notification_subscription AS sns
+		JOIN submission ON submission.id = sns.fk_submission_id
+		WHERE sns.fk_user_id = ?
+		AND submission.deleted_at IS NULL
+		GROUP BY sns.fk_submission_id
+		ORDER BY MAX(sns.created_at) DESC`,
+		uid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sids := make([]int64, 0)
+	for rows.Next() {
+		var sid int64
+		if err := rows.Scan(&sid); err != nil {
+			return nil, err
+		}
+		sids = append(sids, sid)
+	}
+
+	if len(sids) == 0 {
+		return []*types.ExtendedSubmission{}, nil
+	}
+
+	// keep the subscription ordering
+	return d.GetExtendedSubmissionsByIDs(dbs, sids)
+}
+
 // StoreNotification stores a notification message in the database which acts as a queue for the notification service
 func (d *mysqlDAL) StoreNotification(dbs DBSession, msg, notificationType string) error {
 	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
@@ -659,29 +2629,53 @@ func (d *mysqlDAL) GetUsersForUniversalNotification(dbs DBSession, authorID int6
 	return result, nil
 }
 
-// GetOldestUnsentNotification returns oldest unsent notification
+// dueNotificationCondition matches notifications which are neither sent nor given up on and are not waiting for a retry
+const dueNotificationCondition = `sent_at IS NULL AND failed_at IS NULL AND (next_attempt_at IS NULL OR next_attempt_at <= UNIX_TIMESTAMP())`
+
+// GetOldestUnsentNotification returns oldest unsent notification which is due to be sent
 func (d *mysqlDAL) GetOldestUnsentNotification(dbs DBSession) (*types.Notification, error) {
-	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `
-		SELECT id, (SELECT name FROM submission_notification_type WHERE id = fk_submission_notification_type_id), message, created_at, sent_at 
-		FROM submission_notification
-		WHERE sent_at IS NULL
-		ORDER BY created_at LIMIT 1`)
+	notifications, err := d.GetDueNotifications(dbs, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(notifications) == 0 {
+		return nil, sql.ErrNoRows
+	}
 
-	notification := &types.Notification{}
-	var createdAt int64
-	var sentAt *int64
+	return notifications[0], nil
+}
 
-	err := row.Scan(&notification.ID, &notification.Type, &notification.Message, &createdAt, &sentAt)
+// GetDueNotifications returns up to limit oldest unsent notifications which are due to be sent
+func (d *mysqlDAL) GetDueNotifications(dbs DBSession, limit int) ([]*types.Notification, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT id, (SELECT name FROM submission_notification_type WHERE id = fk_submission_notification_type_id), message, created_at, sent_at, attempts
+		FROM submission_notification
+		WHERE `+dueNotificationCondition+`
+		ORDER BY created_at LIMIT ?`,
+		limit)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	result := make([]*types.Notification, 0)
+	for rows.Next() {
+		notification := &types.Notification{}
+		var createdAt int64
+		var sentAt *int64
 
-	notification.CreatedAt = time.Unix(createdAt, 0)
-	if sentAt != nil {
-		notification.SentAt = time.Unix(*sentAt, 0)
+		if err := rows.Scan(&notification.ID, &notification.Type, &notification.Message, &createdAt, &sentAt, &notification.Attempts); err != nil {
+			return nil, err
+		}
+
+		notification.CreatedAt = time.Unix(createdAt, 0)
+		if sentAt != nil {
+			notification.SentAt = time.Unix(*sentAt, 0)
+		}
+		result = append(result, notification)
 	}
 
-	return notification, nil
+	return result, nil
 }
 
 // MarkNotificationAsSent returns oldest unsent notification
@@ -693,8 +2687,34 @@ func (d *mysqlDAL) MarkNotificationAsSent(dbs DBSession, nid int64) error {
 	return err
 }
 
+// MarkNotificationFailed counts a failed delivery of a notification and schedules the next attempt after retryAfter.
+// After constants.NotificationMaxAttempts failed deliveries the notification is marked as permanently failed and never retried.
+func (d *mysqlDAL) MarkNotificationFailed(dbs DBSession, nid int64, retryAfter time.Duration) error {
+	// mysql evaluates single-table SET assignments left to right, so failed_at sees the incremented attempts
+	_, err := dbs.Tx().ExecContext(dbs.Ctx(), `
+		UPDATE submission_notification
+		SET attempts = attempts + 1,
+		    next_attempt_at = UNIX_TIMESTAMP() + ?,
+		    failed_at = IF(attempts >= ?, UNIX_TIMESTAMP(), NULL)
+		WHERE id = ?`,
+		int64(retryAfter.Seconds()), constants.NotificationMaxAttempts, nid)
+
+	return err
+}
+
 // StoreCurationImage stores curation image
 func (d *mysqlDAL) StoreCurationImage(dbs DBSession, c *types.CurationImage) (int64, error) {
+	isAllowedType := false
+	for _, imageType := range constants.GetAllowedCurationImageTypes() {
+		if c.Type == imageType {
+			isAllowedType = true
+			break
+		}
+	}
+	if !isAllowedType {
+		return 0, fmt.Errorf(constants.ErrorInvalidCurationImageType)
+	}
+
 	res, err := dbs.Tx().ExecContext(dbs.Ctx(), `
 		INSERT INTO curation_image (fk_submission_file_id, fk_curation_image_type_id, filename) 
 		VALUES (?, (SELECT id FROM curation_image_type WHERE name = ?), ?)`,
@@ -1030,7 +3050,7 @@ func (d *mysqlDAL) StoreFixesFile(dbs DBSession, s *types.FixesFile) (int64, err
 // GetFilesForFix returns all files of a given fix
 func (d *mysqlDAL) GetFilesForFix(dbs DBSession, fid int64) ([]*types.ExtendedFixesFile, error) {
 	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
-		SELECT fixes_file.id, fk_user_id, discord_user.username, fk_fix_id, original_filename, current_filename, size, created_at, md5sum, sha256sum
+		SELECT fixes_file.id, fk_user_id, COALESCE(discord_user.display_name, discord_user.username), fk_fix_id, original_filename, current_filename, size, created_at, md5sum, sha256sum
 		FROM fixes_file 
 		LEFT JOIN discord_user ON discord_user.id = fk_user_id
 		WHERE fk_fix_id=?`,
@@ -1073,6 +3093,88 @@ func (d *mysqlDAL) DeleteUserSessions(dbs DBSession, uid int64) (int64, error) {
 	return count, nil
 }
 
+// DeleteSessionsOlderThan deletes sessions created before the cutoff, including ones which are still valid
+func (d *mysqlDAL) DeleteSessionsOlderThan(dbs DBSession, cutoff time.Time) (int64, error) {
+	r, err := dbs.Tx().ExecContext(dbs.Ctx(), `
+		DELETE FROM session WHERE created_at IS NULL OR created_at < ?`,
+		cutoff.Unix())
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := r.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// DeleteAllSessions deletes every session, valid or not, forcing a global logout
+func (d *mysqlDAL) DeleteAllSessions(dbs DBSession) (int64, error) {
+	r, err := dbs.Tx().ExecContext(dbs.Ctx(), `DELETE FROM session`)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := r.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetActiveSessionSummary returns counts of unexpired sessions and up to limit users holding the most of them, ties broken by user ID.
+// Limit is capped to a sane maximum. Session secrets are never read.
+func (d *mysqlDAL) GetActiveSessionSummary(dbs DBSession, limit int) (*types.SessionSummary, error) {
+	const maxLimit = 100
+	if limit < 1 || limit > maxLimit {
+		limit = maxLimit
+	}
+	now := time.Now().Unix()
+
+	summary := &types.SessionSummary{}
+	var oldestCreatedAt *int64
+	err := dbs.Tx().QueryRowContext(dbs.Ctx(), `
+		SELECT COUNT(*), COUNT(DISTINCT uid), MIN(created_at), COUNT(*) - COUNT(created_at)
+		FROM session
+		WHERE expires_at > ?`,
+		now).Scan(&summary.ActiveSessions, &summary.DistinctUsers, &oldestCreatedAt, &summary.UnknownAgeSessions)
+	if err != nil {
+		return nil, err
+	}
+	if oldestCreatedAt != nil {
+		t := time.Unix(*oldestCreatedAt, 0)
+		summary.OldestCreatedAt = &t
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT session.uid, COALESCE(discord_user.display_name, discord_user.username, ''), COUNT(*) AS session_count
+		FROM session
+		LEFT JOIN discord_user ON discord_user.id = session.uid
+		WHERE session.expires_at > ?
+		GROUP BY session.uid, discord_user.display_name, discord_user.username
+		ORDER BY session_count DESC, session.uid
+		LIMIT ?`,
+		now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summary.TopUsers = make([]*types.SessionUserCount, 0, limit)
+	for rows.Next() {
+		suc := &types.SessionUserCount{}
+		if err := rows.Scan(&suc.UserID, &suc.Username, &suc.Count); err != nil {
+			return nil, err
+		}
+		summary.TopUsers = append(summary.TopUsers, suc)
+	}
+
+	return summary, nil
+}
+
 // GetTotalCommentsCount returns a total number of comments in the system
 func (d *mysqlDAL) GetTotalCommentsCount(dbs DBSession) (int64, error) {
 	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `
@@ -1151,6 +3253,162 @@ func (d *mysqlDAL) GetTotalFlashfreezeFilesize(dbs DBSession) (int64, error) {
 	return count, nil
 }
 
+// GetTotalStorageUsed returns a total size of all submission files, optionally without files of soft-deleted submissions
+func (d *mysqlDAL) GetTotalStorageUsed(dbs DBSession, excludeDeleted bool) (int64, error) {
+	deletedFilter := ""
+	if excludeDeleted {
+		deletedFilter = ` WHERE submission_file.deleted_at IS NULL AND submission.deleted_at IS NULL`
+	}
+
+	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `
+		SELECT COALESCE(SUM(submission_file.size), 0) FROM submission_file
+		JOIN submission ON submission.id = submission_file.fk_submission_id`+deletedFilter)
+
+	var size int64
+	if err := row.Scan(&size); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// GetStorageUsedByUser returns a total size of submission files uploaded by a given user, optionally without files of soft-deleted submissions
+func (d *mysqlDAL) GetStorageUsedByUser(dbs DBSession, uid int64, excludeDeleted bool) (int64, error) {
+	deletedFilter := ""
+	if excludeDeleted {
+		deletedFilter = ` AND submission_file.deleted_at IS NULL AND submission.deleted_at IS NULL`
+	}
+
+	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `
+		SELECT COALESCE(SUM(submission_file.size), 0) FROM submission_file
+		JOIN submission ON submission.id = submission_file.fk_submission_id
+		WHERE submission_file.fk_user_id = ?`+deletedFilter,
+		uid)
+
+	var size int64
+	if err := row.Scan(&size); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// GetActionUsageStats returns comment counts per action name, split into bot and human authored, within an optional time window
+func (d *mysqlDAL) GetActionUsageStats(dbs DBSession, since, until *time.Time) (map[string]*types.ActionUsage, error) {
+	filters := ""
+	data := []interface{}{constants.ValidatorID}
+	if since != nil {
+		filters += ` AND comment.created_at >= ?`
+		data = append(data, since.Unix())
+	}
+	if until != nil {
+		filters += ` AND comment.created_at < ?`
+		data = append(data, until.Unix())
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT action.name, comment.fk_user_id = ? AS is_bot, COUNT(*)
+		FROM comment
+		JOIN action ON action.id = comment.fk_action_id
+		WHERE comment.deleted_at IS NULL`+filters+`
+		GROUP BY action.name, is_bot`,
+		data...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]*types.ActionUsage)
+	for rows.Next() {
+		var action string
+		var isBot bool
+		var count int64
+		if err := rows.Scan(&action, &isBot, &count); err != nil {
+			return nil, err
+		}
+		usage, ok := result[action]
+		if !ok {
+			usage = &types.ActionUsage{}
+			result[action] = usage
+		}
+		if isBot {
+			usage.BotCount = count
+		} else {
+			usage.HumanCount = count
+		}
+	}
+
+	return result, nil
+}
+
+// GetTopSubmitters returns users with the most submissions first uploaded within a given time window, ties broken by user ID.
+// Limit is capped to a sane maximum.
+func (d *mysqlDAL) GetTopSubmitters(dbs DBSession, since, until time.Time, limit int) ([]*types.SubmitterCount, error) {
+	const maxLimit = 100
+	if limit < 1 || limit > maxLimit {
+		limit = maxLimit
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT discord_user.id, COALESCE(discord_user.display_name, discord_user.username), discord_user.avatar, COUNT(*) AS submission_count
+		FROM submission
+		JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+		JOIN submission_file AS oldest_file ON oldest_file.id = submission_cache.fk_oldest_file_id
+		JOIN discord_user ON discord_user.id = COALESCE(submission.fk_owner_id, oldest_file.fk_user_id)
+		WHERE submission.deleted_at IS NULL
+		AND submission.is_draft = FALSE
+		AND oldest_file.created_at >= ? AND oldest_file.created_at < ?
+		GROUP BY discord_user.id, discord_user.display_name, discord_user.username, discord_user.avatar
+		ORDER BY submission_count DESC, discord_user.id
+		LIMIT ?`,
+		since.Unix(), until.Unix(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*types.SubmitterCount, 0, limit)
+
+	var avatar string
+
+	for rows.Next() {
+		sc := &types.SubmitterCount{}
+		if err := rows.Scan(&sc.UserID, &sc.Username, &avatar, &sc.Count); err != nil {
+			return nil, err
+		}
+		sc.AvatarURL = utils.FormatAvatarURL(sc.UserID, avatar)
+		result = append(result, sc)
+	}
+
+	return result, nil
+}
+
+// CountSubmissionsAwaitingUser counts submissions the user is assigned to but has not yet approved, verified or requested changes on.
+// Rejected submissions have their assignments cleared and are never counted.
+func (d *mysqlDAL) CountSubmissionsAwaitingUser(dbs DBSession, uid int64) (int, error) {
+	id := fmt.Sprintf("%d", uid)
+
+	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `
+		SELECT COUNT(*)
+		FROM submission_cache
+		JOIN submission ON submission.id = submission_cache.fk_submission_id
+		WHERE submission.deleted_at IS NULL
+		AND NOT FIND_IN_SET(?, COALESCE(submission_cache.active_requested_changes_ids, ''))
+		AND (
+			(FIND_IN_SET(?, submission_cache.active_assigned_testing_ids) AND NOT FIND_IN_SET(?, COALESCE(submission_cache.active_approved_ids, '')))
+			OR
+			(FIND_IN_SET(?, submission_cache.active_assigned_verification_ids) AND NOT FIND_IN_SET(?, COALESCE(submission_cache.active_verified_ids, '')))
+		)`,
+		id, id, id, id, id)
+
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 // GetFixesFiles gets fixes files, returns error if input len != output len
 func (d *mysqlDAL) GetFixesFiles(dbs DBSession, ffids []int64) ([]*types.FixesFile, error) {
 	if len(ffids) == 0 {
@@ -1194,3 +3452,44 @@ func (d *mysqlDAL) GetFixesFiles(dbs DBSession, ffids []int64) ([]*types.FixesFi
 
 	return result, nil
 }
+
+// ValidateTagCategories returns tags of a curation whose category is not one of the known tag categories.
+// Tag Categories pair up with Tags by position, so a tag without a category entry is returned too.
+// Categories are matched case-insensitively.
+func (d *mysqlDAL) ValidateTagCategories(dbs DBSession, meta *types.CurationMeta) ([]string, error) {
+	result := make([]string, 0)
+	if meta.Tags == nil {
+		return result, nil
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `SELECT name FROM tag_category`)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		known[strings.ToLower(name)] = true
+	}
+	rows.Close()
+
+	categories := make([]string, 0)
+	if meta.TagCategories != nil {
+		categories = strings.Split(*meta.TagCategories, ";")
+	}
+	for i, tag := range strings.Split(*meta.Tags, ";") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) == 0 {
+			continue
+		}
+		if i >= len(categories) || !known[strings.ToLower(strings.TrimSpace(categories[i]))] {
+			result = append(result, tag)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,286 @@
+package database
+
+import (
+	"fmt"
+	"github.com/Dri0m/flashpoint-submission-system/constants"
+	"github.com/Dri0m/flashpoint-submission-system/types"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pendingSubmissionCondition matches submissions which are still waiting in the queue
+const pendingSubmissionCondition = `submission.deleted_at IS NULL AND submission.is_draft = FALSE AND submission.status NOT IN (?, ?)`
+
+// GenerateQueueReport aggregates the state of pending submissions.
+// All numbers are read within the session's transaction, which under the default repeatable read isolation
+// sees a single snapshot, so they are consistent with each other.
+func (d *mysqlDAL) GenerateQueueReport(dbs DBSession) (*types.QueueReport, error) {
+	report := &types.QueueReport{
+		GeneratedAt: time.Now(),
+		ByStatus:    make(map[string]int64),
+		ByPlatform:  make(map[string]int64),
+		ByAssignee:  make(map[int64]int64),
+	}
+	closed := []interface{}{constants.SubmissionStatusRejected, constants.SubmissionStatusAccepted}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT submission.status, COUNT(*) FROM submission
+		WHERE `+pendingSubmissionCondition+`
+		GROUP BY submission.status`,
+		closed...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		report.ByStatus[status] = count
+		report.PendingCount += count
+	}
+	rows.Close()
+
+	rows, err = dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT COALESCE(meta.platform, ''), COUNT(*) FROM submission
+		JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+		LEFT JOIN curation_meta meta ON meta.fk_submission_file_id = submission_cache.fk_newest_file_id
+		WHERE `+pendingSubmissionCondition+`
+		GROUP BY COALESCE(meta.platform, '')`,
+		closed...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var platform string
+		var count int64
+		if err := rows.Scan(&platform, &count); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		report.ByPlatform[platform] = count
+	}
+	rows.Close()
+
+	rows, err = dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT CONCAT_WS(',', submission_cache.active_assigned_testing_ids, submission_cache.active_assigned_verification_ids) FROM submission
+		JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+		WHERE `+pendingSubmissionCondition+`
+		AND (submission_cache.active_assigned_testing_ids IS NOT NULL OR submission_cache.active_assigned_verification_ids IS NOT NULL)`,
+		closed...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var assignees string
+		if err := rows.Scan(&assignees); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		// a user assigned to both testing and verification counts once per submission
+		seen := make(map[int64]bool)
+		for _, a := range strings.Split(assignees, ",") {
+			if len(a) == 0 {
+				continue
+			}
+			uid, err := strconv.ParseInt(a, 10, 64)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if !seen[uid] {
+				seen[uid] = true
+				report.ByAssignee[uid]++
+			}
+		}
+	}
+	rows.Close()
+
+	var oldestUnreviewed *int64
+	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `
+		SELECT MIN(oldest_file.created_at) FROM submission
+		JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+		JOIN submission_file AS oldest_file ON oldest_file.id = submission_cache.fk_oldest_file_id
+		WHERE `+pendingSubmissionCondition+`
+		AND submission.status = ?`,
+		append(closed, constants.SubmissionStatusNew)...)
+	if err := row.Scan(&oldestUnreviewed); err != nil {
+		return nil, err
+	}
+	if oldestUnreviewed != nil {
+		age := report.GeneratedAt.Sub(time.Unix(*oldestUnreviewed, 0))
+		report.OldestUnreviewedAge = &age
+	}
+
+	row = dbs.Tx().QueryRowContext(dbs.Ctx(), `
+		SELECT COALESCE(SUM(submission_file.size), 0) FROM submission_file
+		JOIN submission ON submission.id = submission_file.fk_submission_id
+		WHERE `+pendingSubmissionCondition+`
+		AND submission_file.deleted_at IS NULL`,
+		closed...)
+	if err := row.Scan(&report.PendingSize); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// GetProcessingLatencyStats returns percentiles of how long submissions took from their first upload to the first review and to acceptance.
+// Each stage counts only submissions which reached it within [since, until).
+// Acceptance is the export into the main database if recorded, the first mark-added action otherwise.
+func (d *mysqlDAL) GetProcessingLatencyStats(dbs DBSession, since, until time.Time) (*types.LatencyStats, error) {
+	stats := &types.LatencyStats{
+		Since: since,
+		Until: until,
+	}
+
+	firstReview, err := getStageLatencies(dbs, `
+		SELECT first_review.reached_at - oldest_file.created_at FROM submission
+		JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+		JOIN submission_file AS oldest_file ON oldest_file.id = submission_cache.fk_oldest_file_id
+		JOIN (SELECT comment.fk_submission_id, MIN(comment.created_at) AS reached_at FROM comment
+		      JOIN action ON action.id = comment.fk_action_id
+		      WHERE comment.deleted_at IS NULL AND comment.fk_user_id NOT IN (?, ?) AND action.name != ?
+		      GROUP BY comment.fk_submission_id) AS first_review ON first_review.fk_submission_id = submission.id
+		WHERE submission.deleted_at IS NULL
+		AND first_review.reached_at >= ? AND first_review.reached_at < ?`,
+		constants.ValidatorID, constants.SystemID, constants.ActionUpload, since.Unix(), until.Unix())
+	if err != nil {
+		return nil, err
+	}
+	stats.FirstReview = latencyPercentiles(firstReview)
+
+	acceptance, err := getStageLatencies(dbs, `
+		SELECT acceptance.reached_at - oldest_file.created_at FROM (
+			SELECT submission.id AS fk_submission_id,
+			       COALESCE(submission.accepted_at, (SELECT MIN(comment.created_at) FROM comment
+			                                         JOIN action ON action.id = comment.fk_action_id
+			                                         WHERE comment.fk_submission_id = submission.id AND comment.deleted_at IS NULL
+			                                         AND action.name = ?)) AS reached_at
+			FROM submission
+			WHERE submission.deleted_at IS NULL) AS acceptance
+		JOIN submission_cache ON submission_cache.fk_submission_id = acceptance.fk_submission_id
+		JOIN submission_file AS oldest_file ON oldest_file.id = submission_cache.fk_oldest_file_id
+		WHERE acceptance.reached_at >= ? AND acceptance.reached_at < ?`,
+		constants.ActionMarkAdded, since.Unix(), until.Unix())
+	if err != nil {
+		return nil, err
+	}
+	stats.Acceptance = latencyPercentiles(acceptance)
+
+	return stats, nil
+}
+
+// getStageLatencies runs a query returning one duration in seconds per row
+func getStageLatencies(dbs DBSession, query string, args ...interface{}) ([]time.Duration, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]time.Duration, 0)
+	for rows.Next() {
+		var seconds int64
+		if err := rows.Scan(&seconds); err != nil {
+			return nil, err
+		}
+		result = append(result, time.Duration(seconds)*time.Second)
+	}
+
+	return result, nil
+}
+
+// latencyPercentiles returns nearest-rank percentiles of given durations, sorting them in place
+func latencyPercentiles(durations []time.Duration) types.LatencyPercentiles {
+	lp := types.LatencyPercentiles{Count: len(durations)}
+	if len(durations) == 0 {
+		return lp
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	rank := func(p int) time.Duration {
+		i := (p*len(durations)+99)/100 - 1
+		return durations[i]
+	}
+	lp.P50 = rank(50)
+	lp.P90 = rank(90)
+
+	return lp
+}
+
+// GetSubmitterGrowth returns the number of new submitters per bucket of time between since and until, including empty buckets.
+// A submitter is new in the bucket their first non-draft submission was uploaded in, see constants.GetAllowedTimeBuckets.
+func (d *mysqlDAL) GetSubmitterGrowth(dbs DBSession, bucket string, since, until time.Time) ([]*types.TimeBucketCount, error) {
+	allowed := false
+	for _, b := range constants.GetAllowedTimeBuckets() {
+		if bucket == b {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf(constants.ErrorInvalidTimeBucket)
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT first_submission.created_at FROM (
+			SELECT MIN(oldest_file.created_at) AS created_at FROM submission
+			JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+			JOIN submission_file AS oldest_file ON oldest_file.id = submission_cache.fk_oldest_file_id
+			WHERE submission.is_draft = FALSE
+			GROUP BY COALESCE(submission.fk_owner_id, oldest_file.fk_user_id)) AS first_submission
+		WHERE first_submission.created_at >= ? AND first_submission.created_at < ?`,
+		since.Unix(), until.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[time.Time]int64)
+	for rows.Next() {
+		var createdAt int64
+		if err := rows.Scan(&createdAt); err != nil {
+			return nil, err
+		}
+		counts[timeBucketStart(time.Unix(createdAt, 0), bucket)]++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.TimeBucketCount, 0)
+	for start := timeBucketStart(since, bucket); start.Before(until); start = nextTimeBucket(start, bucket) {
+		result = append(result, &types.TimeBucketCount{Start: start, Count: counts[start]})
+	}
+
+	return result, nil
+}
+
+// timeBucketStart returns the start of the bucket a time falls in, in UTC
+func timeBucketStart(t time.Time, bucket string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch bucket {
+	case constants.TimeBucketWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case constants.TimeBucketMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+// nextTimeBucket returns the start of the bucket following the one starting at a given time
+func nextTimeBucket(start time.Time, bucket string) time.Time {
+	switch bucket {
+	case constants.TimeBucketWeek:
+		return start.AddDate(0, 0, 7)
+	case constants.TimeBucketMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"database/sql"
+	"github.com/go-sql-driver/mysql"
+	"time"
+)
+
+const defaultLockRetryCount = 3
+const defaultLockRetryBaseDelay = 50 * time.Millisecond
+
+// mysqlErrorLockWaitTimeout is returned when a statement waits too long for a row lock.
+// Only the statement is rolled back, so it is safe to run it again within the same transaction.
+// Deadlocks (1213) roll back the whole transaction and are deliberately not retried here.
+const mysqlErrorLockWaitTimeout = 1205
+
+// SetLockRetryPolicy configures how many times and with what initial delay are write statements retried on lock wait timeouts
+func (d *mysqlDAL) SetLockRetryPolicy(retryCount int, baseDelay time.Duration) {
+	d.lockRetryCount = retryCount
+	d.lockRetryBaseDelay = baseDelay
+}
+
+func isLockWaitTimeout(err error) bool {
+	me, ok := err.(*mysql.MySQLError)
+	return ok && me.Number == mysqlErrorLockWaitTimeout
+}
+
+// execWithRetry executes a write statement, retrying it with exponential backoff on lock wait timeouts
+func (d *mysqlDAL) execWithRetry(dbs DBSession, query string, args ...interface{}) (sql.Result, error) {
+	delay := d.lockRetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		res, err := dbs.Tx().ExecContext(dbs.Ctx(), query, args...)
+		if err == nil || !isLockWaitTimeout(err) || attempt >= d.lockRetryCount {
+			return res, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-dbs.Ctx().Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
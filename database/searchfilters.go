@@ -0,0 +1,379 @@
+package database
+
+import (
+	"fmt"
+	"github.com/Dri0m/flashpoint-submission-system/types"
+	"github.com/Dri0m/flashpoint-submission-system/utils"
+	"strings"
+)
+
+const defaultSearchLimit int64 = 100
+const defaultSearchOffset int64 = 0
+const defaultSearchOrderBy string = "updated_at"
+const defaultSearchSortOrder string = "DESC"
+
+// submissionSearchQuery holds the parts of a submission search derived from a filter.
+// Every clause is added together with the values of its own placeholders, so filters can be combined freely.
+type submissionSearchQuery struct {
+	joinData      []interface{} // values of placeholders in optional joins, which precede the WHERE clause
+	filters       []string
+	data          []interface{}
+	masterFilters []string
+	masterData    []interface{}
+	limit         int64
+	offset        int64
+	orderBy       string
+	sortOrder     string
+}
+
+// addFilter adds a clause restricting submissions
+func (q *submissionSearchQuery) addFilter(clause string, args ...interface{}) {
+	q.filters = append(q.filters, clause)
+	q.data = append(q.data, args...)
+}
+
+// addMasterFilter adds a clause restricting legacy results
+func (q *submissionSearchQuery) addMasterFilter(clause string, args ...interface{}) {
+	q.masterFilters = append(q.masterFilters, clause)
+	q.masterData = append(q.masterData, args...)
+}
+
+// excludeLegacy removes legacy results, for filters which make no sense for them
+func (q *submissionSearchQuery) excludeLegacy() {
+	q.addMasterFilter("(1 = 0)")
+}
+
+// addNullFilter filters on whether a submission cache column is empty
+func (q *submissionSearchQuery) addNullFilter(column, value, nullValue, notNullValue string) {
+	switch value {
+	case nullValue:
+		q.addFilter("(" + column + " IS NULL)")
+	case notNullValue:
+		q.addFilter("(" + column + " IS NOT NULL)")
+	}
+	q.excludeLegacy()
+}
+
+// addUserListFilter filters on whether a comma separated list of user IDs in a submission cache column contains a given user
+func (q *submissionSearchQuery) addUserListFilter(column, value, absentValue, presentValue string, uid int64) {
+	like := utils.FormatLike(fmt.Sprintf("%d", uid))
+	switch value {
+	case absentValue:
+		q.addFilter("("+column+" NOT LIKE ? OR "+column+" IS NULL)", like)
+	case presentValue:
+		q.addFilter("("+column+" LIKE ?)", like)
+	}
+	q.excludeLegacy()
+}
+
+// addInFilter restricts a column to any of the given values
+func (q *submissionSearchQuery) addInFilter(column string, values []string) {
+	args := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		args = append(args, v)
+	}
+	q.addFilter(`(`+column+` IN(?`+strings.Repeat(",?", len(values)-1)+`))`, args...)
+	q.excludeLegacy()
+}
+
+// buildSubmissionSearchQuery translates a filter into query clauses, uid is the user doing the search
+func buildSubmissionSearchQuery(filter *types.SubmissionsFilter, uid int64) *submissionSearchQuery {
+	q := &submissionSearchQuery{
+		joinData:      make([]interface{}, 0),
+		filters:       make([]string, 0),
+		data:          make([]interface{}, 0),
+		masterFilters: make([]string, 0),
+		masterData:    make([]interface{}, 0),
+		limit:         defaultSearchLimit,
+		offset:        defaultSearchOffset,
+		orderBy:       defaultSearchOrderBy,
+		sortOrder:     defaultSearchSortOrder,
+	}
+
+	// drafts are only visible to their owner, filtering by submitter narrows the results but never shows drafts of others
+	q.addFilter("(submission.is_draft = FALSE OR COALESCE(submission.fk_owner_id, uploader.id) = ?)", uid)
+
+	if filter == nil {
+		return q
+	}
+
+	if len(filter.SubmissionIDs) > 0 {
+		args := make([]interface{}, 0, len(filter.SubmissionIDs))
+		for _, sid := range filter.SubmissionIDs {
+			args = append(args, sid)
+		}
+		q.addFilter(`(submission.id IN(?`+strings.Repeat(`,?`, len(filter.SubmissionIDs)-1)+`))`, args...)
+		q.excludeLegacy()
+	}
+	if filter.SubmitterID != nil {
+		q.addFilter("(COALESCE(submission.fk_owner_id, uploader.id) = ?)", *filter.SubmitterID)
+		q.excludeLegacy()
+	}
+	if len(filter.SubmitterIDs) > 0 {
+		args := make([]interface{}, 0, len(filter.SubmitterIDs))
+		for _, submitterID := range filter.SubmitterIDs {
+			args = append(args, submitterID)
+		}
+		q.addFilter(`(COALESCE(submission.fk_owner_id, uploader.id) IN(?`+strings.Repeat(`,?`, len(filter.SubmitterIDs)-1)+`))`, args...)
+		q.excludeLegacy()
+	}
+	if filter.TitlePartial != nil {
+		q.addFilter("(meta.title LIKE ? OR meta.alternate_titles LIKE ?)", utils.FormatLike(*filter.TitlePartial), utils.FormatLike(*filter.TitlePartial))
+		q.addMasterFilter("(title LIKE ? OR alternate_titles LIKE ?)", utils.FormatLike(*filter.TitlePartial), utils.FormatLike(*filter.TitlePartial))
+	}
+	if filter.SubmitterUsernamePartial != nil {
+		tableName := `COALESCE(uploader.display_name, uploader.username)`
+		q.filters, q.masterFilters, q.data, q.masterData = addMultifilter(
+			tableName, nil, *filter.SubmitterUsernamePartial, q.filters, q.masterFilters, q.data, q.masterData)
+		q.excludeLegacy()
+	}
+	if filter.PlatformPartial != nil {
+		tableName := `meta.platform`
+		masterTableName := `platform`
+		q.filters, q.masterFilters, q.data, q.masterData = addMultifilter(
+			tableName, &masterTableName, *filter.PlatformPartial, q.filters, q.masterFilters, q.data, q.masterData)
+	}
+	if filter.LibraryPartial != nil {
+		q.addFilter("(meta.library LIKE ?)", utils.FormatLike(*filter.LibraryPartial))
+		q.addMasterFilter("(library LIKE ?)", utils.FormatLike(*filter.LibraryPartial))
+	}
+	if filter.OriginalFilenamePartialAny != nil {
+		q.addFilter("(submission_cache.original_filename_sequence LIKE ?)", utils.FormatLike(*filter.OriginalFilenamePartialAny))
+		q.excludeLegacy()
+	}
+	if filter.CurrentFilenamePartialAny != nil {
+		q.addFilter("(submission_cache.current_filename_sequence LIKE ?)", utils.FormatLike(*filter.CurrentFilenamePartialAny))
+		q.excludeLegacy()
+	}
+	if filter.MD5SumPartialAny != nil {
+		q.addFilter("(submission_cache.md5sum_sequence LIKE ?)", utils.FormatLike(*filter.MD5SumPartialAny))
+		q.excludeLegacy()
+	}
+	if filter.SHA256SumPartialAny != nil {
+		q.addFilter("(submission_cache.sha256sum_sequence LIKE ?)", utils.FormatLike(*filter.SHA256SumPartialAny))
+		q.excludeLegacy()
+	}
+	if len(filter.BotActions) != 0 {
+		q.addInFilter("submission_cache.bot_action", filter.BotActions)
+	}
+	if len(filter.Status) != 0 {
+		q.addInFilter("submission.status", filter.Status)
+	}
+	if len(filter.SubmissionLevels) != 0 {
+		q.addInFilter("(SELECT name FROM submission_level WHERE id = submission.fk_submission_level_id)", filter.SubmissionLevels)
+	}
+	if len(filter.ActionsAfterMyLastComment) != 0 {
+		// the joined subquery matches on the searching user
+		q.joinData = append(q.joinData, uid, uid)
+
+		foundAny := false
+		for _, aamlc := range filter.ActionsAfterMyLastComment {
+			if aamlc == "any" {
+				foundAny = true
+			}
+		}
+		if foundAny {
+			q.addFilter(`(actions_after_my_last_comment.user_action_string IS NOT NULL)`)
+		} else {
+			args := make([]interface{}, 0, len(filter.ActionsAfterMyLastComment))
+			for _, aamlc := range filter.ActionsAfterMyLastComment {
+				args = append(args, aamlc)
+			}
+			q.addFilter(`(REGEXP_LIKE (actions_after_my_last_comment.user_action_string, CONCAT(CONCAT(?)`+strings.Repeat(", '|', CONCAT(?)", len(filter.ActionsAfterMyLastComment)-1)+`)))`, args...)
+		}
+		q.excludeLegacy()
+	}
+
+	if filter.ResultsPerPage != nil {
+		q.limit = *filter.ResultsPerPage
+	}
+	if filter.Page != nil {
+		q.offset = (*filter.Page - 1) * q.limit
+	}
+
+	if filter.AssignedStatusTesting != nil {
+		q.addNullFilter("submission_cache.active_assigned_testing_ids", *filter.AssignedStatusTesting, "unassigned", "assigned")
+	}
+	if filter.AssignedStatusVerification != nil {
+		q.addNullFilter("submission_cache.active_assigned_verification_ids", *filter.AssignedStatusVerification, "unassigned", "assigned")
+	}
+	if filter.RequestedChangedStatus != nil {
+		q.addNullFilter("submission_cache.active_requested_changes_ids", *filter.RequestedChangedStatus, "none", "ongoing")
+	}
+	if filter.AcceptedStatus != nil {
+		q.addNullFilter("submission.accepted_at", *filter.AcceptedStatus, "unaccepted", "accepted")
+	}
+	if filter.ApprovalsStatus != nil {
+		q.addNullFilter("submission_cache.active_approved_ids", *filter.ApprovalsStatus, "none", "approved")
+	}
+	if filter.VerificationStatus != nil {
+		q.addNullFilter("submission_cache.active_verified_ids", *filter.VerificationStatus, "none", "verified")
+	}
+
+	if filter.AssignedStatusTestingMe != nil {
+		q.addUserListFilter("submission_cache.active_assigned_testing_ids", *filter.AssignedStatusTestingMe, "unassigned", "assigned", uid)
+	}
+	if filter.AssignedStatusVerificationMe != nil {
+		q.addUserListFilter("submission_cache.active_assigned_verification_ids", *filter.AssignedStatusVerificationMe, "unassigned", "assigned", uid)
+	}
+	if filter.RequestedChangedStatusMe != nil {
+		q.addUserListFilter("submission_cache.active_requested_changes_ids", *filter.RequestedChangedStatusMe, "none", "ongoing", uid)
+	}
+	if filter.ApprovalsStatusMe != nil {
+		q.addUserListFilter("submission_cache.active_approved_ids", *filter.ApprovalsStatusMe, "no", "yes", uid)
+	}
+	if filter.VerificationStatusMe != nil {
+		q.addUserListFilter("submission_cache.active_verified_ids", *filter.VerificationStatusMe, "no", "yes", uid)
+	}
+
+	if filter.AssignedStatusUserID != nil {
+		userID := *filter.AssignedStatusUserID
+		if filter.AssignedStatusTestingUser != nil {
+			q.addUserListFilter("submission_cache.active_assigned_testing_ids", *filter.AssignedStatusTestingUser, "unassigned", "assigned", userID)
+		}
+		if filter.AssignedStatusVerificationUser != nil {
+			q.addUserListFilter("submission_cache.active_assigned_verification_ids", *filter.AssignedStatusVerificationUser, "unassigned", "assigned", userID)
+		}
+		if filter.RequestedChangedStatusUser != nil {
+			q.addUserListFilter("submission_cache.active_requested_changes_ids", *filter.RequestedChangedStatusUser, "none", "ongoing", userID)
+		}
+		if filter.ApprovalsStatusUser != nil {
+			q.addUserListFilter("submission_cache.active_approved_ids", *filter.ApprovalsStatusUser, "no", "yes", userID)
+		}
+		if filter.VerificationStatusUser != nil {
+			q.addUserListFilter("submission_cache.active_verified_ids", *filter.VerificationStatusUser, "no", "yes", userID)
+		}
+	}
+
+	if filter.IsExtreme != nil {
+		q.addFilter("(meta.extreme = ?)", *filter.IsExtreme)
+		q.addMasterFilter("(extreme = ?)", *filter.IsExtreme)
+	}
+	if filter.ExcludeExtreme {
+		q.addFilter("(meta.extreme IS NULL OR meta.extreme != ?)", "yes")
+		q.addMasterFilter("(extreme IS NULL OR extreme != ?)", "yes")
+	}
+	if len(filter.DistinctActions) != 0 {
+		args := make([]interface{}, 0, len(filter.DistinctActions))
+		for _, da := range filter.DistinctActions {
+			args = append(args, da)
+		}
+		q.addFilter(`(REGEXP_LIKE (submission_cache.distinct_actions, CONCAT(CONCAT(?)`+strings.Repeat(", '|', CONCAT(?)", len(filter.DistinctActions)-1)+`)))`, args...)
+		q.excludeLegacy()
+	}
+	if len(filter.DistinctActionsNot) != 0 {
+		args := make([]interface{}, 0, len(filter.DistinctActionsNot))
+		for _, da := range filter.DistinctActionsNot {
+			args = append(args, da)
+		}
+		q.addFilter(`(NOT REGEXP_LIKE (submission_cache.distinct_actions, CONCAT(CONCAT(?)`+strings.Repeat(", '|', CONCAT(?)", len(filter.DistinctActionsNot)-1)+`)))`, args...)
+		q.excludeLegacy()
+	}
+	if filter.LaunchCommandFuzzy != nil { // TODO not really fuzzy is it
+		q.addFilter("(meta.launch_command LIKE ?)", utils.FormatLike(*filter.LaunchCommandFuzzy))
+		q.addMasterFilter("(launch_command LIKE ?)", utils.FormatLike(*filter.LaunchCommandFuzzy))
+	}
+	if filter.LastUploaderNotMe != nil {
+		if *filter.LastUploaderNotMe == "yes" {
+			q.addFilter("(uploader.id != ?)", uid)
+		}
+		q.excludeLegacy()
+	}
+	if filter.OrderBy != nil {
+		switch *filter.OrderBy {
+		case "uploaded":
+			q.orderBy = "created_at"
+		case "updated":
+			q.orderBy = "updated_at"
+		case "size":
+			q.orderBy = "newest_file_size"
+		case "activity":
+			q.orderBy = "last_activity_at"
+		case "priority":
+			// higher priority always goes first, the sort order only applies to ties
+			q.orderBy = "priority DESC, updated_at"
+		case "random":
+			// sorts the whole filtered result, so it gets slow on broad filters
+			q.orderBy = "RAND()"
+		}
+	}
+	if filter.AscDesc != nil {
+		switch *filter.AscDesc {
+		case "asc":
+			q.sortOrder = "ASC"
+		case "desc":
+			q.sortOrder = "DESC"
+		}
+	}
+	if filter.SubscribedMe != nil {
+		if *filter.SubscribedMe == "yes" {
+			q.addFilter("(sns.fk_user_id = ?)", uid)
+		}
+		q.excludeLegacy()
+	}
+	if filter.Tag != nil {
+		q.addFilter("(EXISTS (SELECT 1 FROM curation_tag WHERE curation_tag.fk_submission_file_id = newest_file.id AND curation_tag.name = ?))", *filter.Tag)
+		q.excludeLegacy()
+	}
+	if filter.Language != nil {
+		q.addFilter("(EXISTS (SELECT 1 FROM curation_language WHERE curation_language.fk_submission_file_id = newest_file.id AND curation_language.code = ?))", *filter.Language)
+		q.excludeLegacy()
+	}
+	if filter.CurationStatus != nil {
+		q.addFilter("(meta.status = ?)", *filter.CurationStatus)
+		q.addMasterFilter("(status = ?)", *filter.CurationStatus)
+	}
+	if filter.LastActionBefore != nil {
+		q.addFilter("(newest_comment.created_at IS NULL OR newest_comment.created_at < ?)", filter.LastActionBefore.Unix())
+		q.addMasterFilter("(date_modified IS NULL OR date_modified < ?)", filter.LastActionBefore.Unix())
+	}
+	if filter.LastActionAfter != nil {
+		q.addFilter("(newest_comment.created_at > ?)", filter.LastActionAfter.Unix())
+		q.addMasterFilter("(date_modified > ?)", filter.LastActionAfter.Unix())
+	}
+	if filter.DeadlineBefore != nil {
+		q.addFilter("(submission.review_deadline < ?)", filter.DeadlineBefore.Unix())
+		q.excludeLegacy()
+	}
+	if filter.Overdue != nil {
+		// overdue means the deadline has passed and the submission has no active approval
+		if *filter.Overdue {
+			q.addFilter("(submission.review_deadline < UNIX_TIMESTAMP() AND submission_cache.active_approved_ids IS NULL)")
+		} else {
+			q.addFilter("(submission.review_deadline IS NULL OR submission.review_deadline >= UNIX_TIMESTAMP() OR submission_cache.active_approved_ids IS NOT NULL)")
+		}
+		q.excludeLegacy()
+	}
+	if filter.MinPriority != nil {
+		q.addFilter("(submission.priority >= ?)", *filter.MinPriority)
+		q.excludeLegacy()
+	}
+	if filter.SubmitterDeauthorized {
+		q.addFilter("(NOT EXISTS (SELECT 1 FROM discord_user_role WHERE discord_user_role.fk_uid = COALESCE(submission.fk_owner_id, uploader.id)))")
+		q.excludeLegacy()
+	}
+	if filter.CommentedByUID != nil {
+		q.addFilter("(EXISTS (SELECT 1 FROM comment WHERE comment.fk_submission_id = submission.id AND comment.fk_user_id = ? AND comment.deleted_at IS NULL))", *filter.CommentedByUID)
+		q.excludeLegacy()
+	}
+	if filter.NotCommentedByUID != nil {
+		q.addFilter("(NOT EXISTS (SELECT 1 FROM comment WHERE comment.fk_submission_id = submission.id AND comment.fk_user_id = ? AND comment.deleted_at IS NULL))", *filter.NotCommentedByUID)
+		q.excludeLegacy()
+	}
+	if filter.MetaComplete {
+		q.addFilter("(TRIM(meta.title) != '' AND TRIM(meta.platform) != '' AND TRIM(meta.library) != '' AND TRIM(meta.launch_command) != '')")
+		q.excludeLegacy()
+	}
+	if filter.ExcludeLegacy {
+		q.excludeLegacy()
+	}
+	// keyset pagination, relies on the default ordering so the tuple comparison matches the sort
+	if filter.AfterUpdatedAt != nil && filter.AfterID != nil {
+		q.addFilter("((newest_comment.created_at, submission.id) < (?, ?))", *filter.AfterUpdatedAt, *filter.AfterID)
+		q.addMasterFilter("((date_modified, -1) < (?, ?))", *filter.AfterUpdatedAt, *filter.AfterID)
+		q.offset = defaultSearchOffset
+		q.sortOrder = defaultSearchSortOrder + ", submission_id " + defaultSearchSortOrder
+	}
+
+	return q
+}
@@ -0,0 +1,250 @@
+package database
+
+import (
+	"github.com/Dri0m/flashpoint-submission-system/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_buildSubmissionSearchQuery(t *testing.T) {
+	var uid int64 = 1
+	var otherUID int64 = 2
+
+	str := func(s string) *string { return &s }
+	i64 := func(i int64) *int64 { return &i }
+	bl := func(b bool) *bool { return &b }
+	tm := time.Unix(1600000000, 0)
+
+	tests := []struct {
+		name          string
+		filter        *types.SubmissionsFilter
+		wantFilters   []string // clauses expected among the filters, besides the draft visibility one
+		wantJoinData  int
+		wantLegacy    bool
+		wantLimit     int64
+		wantOffset    int64
+		wantSortOrder string
+	}{
+		{
+			name: "assigned to me, in review, title",
+			filter: &types.SubmissionsFilter{
+				AssignedStatusTestingMe: str("assigned"),
+				Status:                  []string{"in-review"},
+				TitlePartial:            str("foo"),
+			},
+			wantFilters: []string{
+				"(submission_cache.active_assigned_testing_ids LIKE ?)",
+				"(submission.status IN(?))",
+				"(meta.title LIKE ? OR meta.alternate_titles LIKE ?)",
+			},
+		},
+		{
+			name: "not subscribed, bot actions, library, platform",
+			filter: &types.SubmissionsFilter{
+				SubscribedMe:    str("no"),
+				BotActions:      []string{"approve", "request-changes"},
+				LibraryPartial:  str("arcade"),
+				PlatformPartial: str("Flash, !HTML5"),
+			},
+			wantFilters: []string{
+				"(submission_cache.bot_action IN(?,?))",
+				"(meta.library LIKE ?)",
+				"((meta.platform LIKE ?))",
+				"((meta.platform NOT LIKE ?))",
+			},
+		},
+		{
+			name: "actions after my last comment, status, submitter, distinct actions",
+			filter: &types.SubmissionsFilter{
+				ActionsAfterMyLastComment: []string{"approve", "comment"},
+				Status:                    []string{"new", "needs-changes"},
+				SubmitterID:               i64(otherUID),
+				DistinctActions:           []string{"verify"},
+			},
+			wantFilters: []string{
+				"(REGEXP_LIKE (actions_after_my_last_comment.user_action_string, CONCAT(CONCAT(?), '|', CONCAT(?))))",
+				"(submission.status IN(?,?))",
+				"(COALESCE(submission.fk_owner_id, uploader.id) = ?)",
+				"(REGEXP_LIKE (submission_cache.distinct_actions, CONCAT(CONCAT(?))))",
+			},
+			wantJoinData: 2,
+		},
+		{
+			name: "user filter without user, extreme, last action window",
+			filter: &types.SubmissionsFilter{
+				AssignedStatusTestingUser: str("assigned"),
+				IsExtreme:                 str("no"),
+				LastActionBefore:          &tm,
+				LastActionAfter:           &tm,
+			},
+			wantFilters: []string{
+				"(meta.extreme = ?)",
+				"(newest_comment.created_at IS NULL OR newest_comment.created_at < ?)",
+				"(newest_comment.created_at > ?)",
+			},
+			wantLegacy: true,
+		},
+		{
+			name: "min priority, priority ordering",
+			filter: &types.SubmissionsFilter{
+				MinPriority: i64(1),
+				OrderBy:     str("priority"),
+				AscDesc:     str("asc"),
+			},
+			wantFilters: []string{
+				"(submission.priority >= ?)",
+			},
+			wantSortOrder: "ASC",
+		},
+		{
+			name: "deauthorized submitter, title",
+			filter: &types.SubmissionsFilter{
+				SubmitterDeauthorized: true,
+				TitlePartial:          str("foo"),
+			},
+			wantFilters: []string{
+				"(NOT EXISTS (SELECT 1 FROM discord_user_role WHERE discord_user_role.fk_uid = COALESCE(submission.fk_owner_id, uploader.id)))",
+				"(meta.title LIKE ? OR meta.alternate_titles LIKE ?)",
+			},
+		},
+		{
+			name: "single and multiple submitters",
+			filter: &types.SubmissionsFilter{
+				SubmitterID:  i64(otherUID),
+				SubmitterIDs: []int64{uid, otherUID, 3},
+			},
+			wantFilters: []string{
+				"(COALESCE(submission.fk_owner_id, uploader.id) = ?)",
+				"(COALESCE(submission.fk_owner_id, uploader.id) IN(?,?,?))",
+			},
+		},
+		{
+			name: "commented and not commented by user",
+			filter: &types.SubmissionsFilter{
+				CommentedByUID:    i64(otherUID),
+				NotCommentedByUID: i64(uid),
+			},
+			wantFilters: []string{
+				"(EXISTS (SELECT 1 FROM comment WHERE comment.fk_submission_id = submission.id AND comment.fk_user_id = ? AND comment.deleted_at IS NULL))",
+				"(NOT EXISTS (SELECT 1 FROM comment WHERE comment.fk_submission_id = submission.id AND comment.fk_user_id = ? AND comment.deleted_at IS NULL))",
+			},
+		},
+		{
+			name: "complete meta",
+			filter: &types.SubmissionsFilter{
+				MetaComplete: true,
+			},
+			wantFilters: []string{
+				"(TRIM(meta.title) != '' AND TRIM(meta.platform) != '' AND TRIM(meta.library) != '' AND TRIM(meta.launch_command) != '')",
+			},
+		},
+		{
+			name: "tag and language",
+			filter: &types.SubmissionsFilter{
+				Tag:      str("Puzzle"),
+				Language: str("ja"),
+			},
+			wantFilters: []string{
+				"(EXISTS (SELECT 1 FROM curation_tag WHERE curation_tag.fk_submission_file_id = newest_file.id AND curation_tag.name = ?))",
+				"(EXISTS (SELECT 1 FROM curation_language WHERE curation_language.fk_submission_file_id = newest_file.id AND curation_language.code = ?))",
+			},
+		},
+		{
+			name: "curation status",
+			filter: &types.SubmissionsFilter{
+				CurationStatus: str("Playable"),
+			},
+			wantFilters: []string{
+				"(meta.status = ?)",
+			},
+			wantLegacy: true,
+		},
+		{
+			name: "user filters, overdue, keyset pagination, paging",
+			filter: &types.SubmissionsFilter{
+				AssignedStatusUserID:       i64(otherUID),
+				AssignedStatusTestingUser:  str("unassigned"),
+				ApprovalsStatusUser:        str("yes"),
+				VerificationStatus:         str("none"),
+				Overdue:                    bl(true),
+				AfterUpdatedAt:             i64(1600000000),
+				AfterID:                    i64(42),
+				ResultsPerPage:             i64(10),
+				Page:                       i64(3),
+				AssignedStatusVerification: str("assigned"),
+			},
+			wantFilters: []string{
+				"(submission_cache.active_assigned_testing_ids NOT LIKE ? OR submission_cache.active_assigned_testing_ids IS NULL)",
+				"(submission_cache.active_approved_ids LIKE ?)",
+				"(submission_cache.active_verified_ids IS NULL)",
+				"(submission_cache.active_assigned_verification_ids IS NOT NULL)",
+				"(submission.review_deadline < UNIX_TIMESTAMP() AND submission_cache.active_approved_ids IS NULL)",
+				"((newest_comment.created_at, submission.id) < (?, ?))",
+			},
+			wantLimit:     10,
+			wantOffset:    defaultSearchOffset,
+			wantSortOrder: defaultSearchSortOrder + ", submission_id " + defaultSearchSortOrder,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := buildSubmissionSearchQuery(tt.filter, uid)
+
+			if len(q.filters) == 0 || q.filters[0] != "(submission.is_draft = FALSE OR COALESCE(submission.fk_owner_id, uploader.id) = ?)" || q.data[0] != uid {
+				t.Errorf("buildSubmissionSearchQuery() filters = %v, data = %v, want drafts of user %d only", q.filters, q.data, uid)
+			}
+			if len(q.filters) != len(tt.wantFilters)+1 {
+				t.Errorf("buildSubmissionSearchQuery() filters = %v, want %d filters", q.filters, len(tt.wantFilters)+1)
+			}
+			for _, want := range tt.wantFilters {
+				found := false
+				for _, f := range q.filters {
+					if f == want {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("buildSubmissionSearchQuery() filters = %v, missing %s", q.filters, want)
+				}
+			}
+
+			if got := strings.Count(strings.Join(q.filters, " AND "), "?"); got != len(q.data) {
+				t.Errorf("buildSubmissionSearchQuery() has %d filter placeholders and %d values", got, len(q.data))
+			}
+			if got := strings.Count(strings.Join(q.masterFilters, " AND "), "?"); got != len(q.masterData) {
+				t.Errorf("buildSubmissionSearchQuery() has %d legacy filter placeholders and %d values", got, len(q.masterData))
+			}
+			if len(q.joinData) != tt.wantJoinData {
+				t.Errorf("buildSubmissionSearchQuery() joinData = %v, want %d values", q.joinData, tt.wantJoinData)
+			}
+
+			legacy := true
+			for _, f := range q.masterFilters {
+				if f == "(1 = 0)" {
+					legacy = false
+				}
+			}
+			if legacy != tt.wantLegacy {
+				t.Errorf("buildSubmissionSearchQuery() legacy results = %v, want %v", legacy, tt.wantLegacy)
+			}
+
+			wantLimit := tt.wantLimit
+			if wantLimit == 0 {
+				wantLimit = defaultSearchLimit
+			}
+			if q.limit != wantLimit || q.offset != tt.wantOffset {
+				t.Errorf("buildSubmissionSearchQuery() limit, offset = %d, %d, want %d, %d", q.limit, q.offset, wantLimit, tt.wantOffset)
+			}
+
+			wantSortOrder := tt.wantSortOrder
+			if wantSortOrder == "" {
+				wantSortOrder = defaultSearchSortOrder
+			}
+			if q.sortOrder != wantSortOrder {
+				t.Errorf("buildSubmissionSearchQuery() sortOrder = %s, want %s", q.sortOrder, wantSortOrder)
+			}
+		})
+	}
+}
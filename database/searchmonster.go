@@ -1,7 +1,10 @@
 package database
 
 import (
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"github.com/Dri0m/flashpoint-submission-system/constants"
 	"github.com/Dri0m/flashpoint-submission-system/types"
 	"github.com/Dri0m/flashpoint-submission-system/utils"
 	"strconv"
@@ -12,326 +15,22 @@ import (
 
 // SearchSubmissions returns extended submissions based on given filter
 func (d *mysqlDAL) SearchSubmissions(dbs DBSession, filter *types.SubmissionsFilter) ([]*types.ExtendedSubmission, int64, error) {
-	uid := utils.UserID(dbs.Ctx()) // TODO this should be passed as param
-
-	filters := make([]string, 0)
-	masterFilters := make([]string, 0)
-	data := make([]interface{}, 0)
-	masterData := make([]interface{}, 0)
-
-	if filter != nil && len(filter.ActionsAfterMyLastComment) != 0 {
-		data = append(data, uid, uid)
-	}
-
-	const defaultLimit int64 = 100
-	const defaultOffset int64 = 0
-	const defaultOrderBy string = "updated_at"
-	const defaultSortOrder string = "DESC"
-
-	currentLimit := defaultLimit
-	currentOffset := defaultOffset
-	currentOrderBy := defaultOrderBy
-	currentSortOrder := defaultSortOrder
-
-	if filter != nil {
-		if len(filter.SubmissionIDs) > 0 {
-			filters = append(filters, `(submission.id IN(?`+strings.Repeat(`,?`, len(filter.SubmissionIDs)-1)+`))`)
-			for _, sid := range filter.SubmissionIDs {
-				data = append(data, sid)
-			}
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.SubmitterID != nil {
-			filters = append(filters, "(uploader.id = ?)")
-			data = append(data, *filter.SubmitterID)
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.TitlePartial != nil {
-			filters = append(filters, "(meta.title LIKE ? OR meta.alternate_titles LIKE ?)")
-			data = append(data, utils.FormatLike(*filter.TitlePartial), utils.FormatLike(*filter.TitlePartial))
-			masterFilters = append(masterFilters, "(title LIKE ? OR alternate_titles LIKE ?)")
-			masterData = append(masterData, utils.FormatLike(*filter.TitlePartial), utils.FormatLike(*filter.TitlePartial))
-		}
-		if filter.SubmitterUsernamePartial != nil {
-			tableName := `uploader.username`
-			filters, masterFilters, data, masterData = addMultifilter(
-				tableName, nil, *filter.SubmitterUsernamePartial, filters, masterFilters, data, masterData)
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.PlatformPartial != nil {
-			tableName := `meta.platform`
-			masterTableName := `platform`
-			filters, masterFilters, data, masterData = addMultifilter(
-				tableName, &masterTableName, *filter.PlatformPartial, filters, masterFilters, data, masterData)
-		}
-		if filter.LibraryPartial != nil {
-			filters = append(filters, "(meta.library LIKE ?)")
-			data = append(data, utils.FormatLike(*filter.LibraryPartial))
-			masterFilters = append(masterFilters, "(library LIKE ?)")
-			masterData = append(masterData, utils.FormatLike(*filter.LibraryPartial))
-		}
-		if filter.OriginalFilenamePartialAny != nil {
-			filters = append(filters, "(submission_cache.original_filename_sequence LIKE ?)")
-			data = append(data, utils.FormatLike(*filter.OriginalFilenamePartialAny))
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.CurrentFilenamePartialAny != nil {
-			filters = append(filters, "(submission_cache.current_filename_sequence LIKE ?)")
-			data = append(data, utils.FormatLike(*filter.CurrentFilenamePartialAny))
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.MD5SumPartialAny != nil {
-			filters = append(filters, "(submission_cache.md5sum_sequence LIKE ?)")
-			data = append(data, utils.FormatLike(*filter.MD5SumPartialAny))
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.SHA256SumPartialAny != nil {
-			filters = append(filters, "(submission_cache.sha256sum_sequence LIKE ?)")
-			data = append(data, utils.FormatLike(*filter.SHA256SumPartialAny))
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if len(filter.BotActions) != 0 {
-			filters = append(filters, `(submission_cache.bot_action IN(?`+strings.Repeat(",?", len(filter.BotActions)-1)+`))`)
-			for _, ba := range filter.BotActions {
-				data = append(data, ba)
-			}
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if len(filter.SubmissionLevels) != 0 {
-			filters = append(filters, `((SELECT name FROM submission_level WHERE id = submission.fk_submission_level_id) IN(?`+strings.Repeat(",?", len(filter.SubmissionLevels)-1)+`))`)
-			for _, ba := range filter.SubmissionLevels {
-				data = append(data, ba)
-			}
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if len(filter.ActionsAfterMyLastComment) != 0 {
-			foundAny := false
-			for _, aamlc := range filter.ActionsAfterMyLastComment {
-				if aamlc == "any" {
-					foundAny = true
-				}
-			}
-			if foundAny {
-				filters = append(filters, `(actions_after_my_last_comment.user_action_string IS NOT NULL)`)
-			} else {
-				filters = append(filters, `(REGEXP_LIKE (actions_after_my_last_comment.user_action_string, CONCAT(CONCAT(?)`+strings.Repeat(", '|', CONCAT(?)", len(filter.ActionsAfterMyLastComment)-1)+`)))`)
-				for _, aamlc := range filter.ActionsAfterMyLastComment {
-					data = append(data, aamlc)
-				}
-			}
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-
-		if filter.ResultsPerPage != nil {
-			currentLimit = *filter.ResultsPerPage
-		} else {
-			currentLimit = defaultLimit
-		}
-		if filter.Page != nil {
-			currentOffset = (*filter.Page - 1) * currentLimit
-		} else {
-			currentOffset = defaultOffset
-		}
-		if filter.AssignedStatusTesting != nil {
-			if *filter.AssignedStatusTesting == "unassigned" {
-				filters = append(filters, "(submission_cache.active_assigned_testing_ids IS NULL)")
-			} else if *filter.AssignedStatusTesting == "assigned" {
-				filters = append(filters, "(submission_cache.active_assigned_testing_ids IS NOT NULL)")
-			}
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.AssignedStatusVerification != nil {
-			if *filter.AssignedStatusVerification == "unassigned" {
-				filters = append(filters, "(submission_cache.active_assigned_verification_ids IS NULL)")
-			} else if *filter.AssignedStatusVerification == "assigned" {
-				filters = append(filters, "(submission_cache.active_assigned_verification_ids IS NOT NULL)")
-			}
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.RequestedChangedStatus != nil {
-			if *filter.RequestedChangedStatus == "none" {
-				filters = append(filters, "(submission_cache.active_requested_changes_ids IS NULL)")
-			} else if *filter.RequestedChangedStatus == "ongoing" {
-				filters = append(filters, "(submission_cache.active_requested_changes_ids IS NOT NULL)")
-			}
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.ApprovalsStatus != nil {
-			if *filter.ApprovalsStatus == "none" {
-				filters = append(filters, "(submission_cache.active_approved_ids IS NULL)")
-			} else if *filter.ApprovalsStatus == "approved" {
-				filters = append(filters, "(submission_cache.active_approved_ids IS NOT NULL)")
-			}
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.VerificationStatus != nil {
-			if *filter.VerificationStatus == "none" {
-				filters = append(filters, "(submission_cache.active_verified_ids IS NULL)")
-			} else if *filter.VerificationStatus == "verified" {
-				filters = append(filters, "(submission_cache.active_verified_ids IS NOT NULL)")
-			}
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.AssignedStatusTestingMe != nil {
-			if *filter.AssignedStatusTestingMe == "unassigned" {
-				filters = append(filters, "(submission_cache.active_assigned_testing_ids NOT LIKE ? OR submission_cache.active_assigned_testing_ids IS NULL)")
-			} else if *filter.AssignedStatusTestingMe == "assigned" {
-				filters = append(filters, "(submission_cache.active_assigned_testing_ids LIKE ?)")
-			}
-			data = append(data, utils.FormatLike(fmt.Sprintf("%d", uid)))
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.AssignedStatusVerificationMe != nil {
-			if *filter.AssignedStatusVerificationMe == "unassigned" {
-				filters = append(filters, "(submission_cache.active_assigned_verification_ids NOT LIKE ? OR submission_cache.active_assigned_verification_ids IS NULL)")
-			} else if *filter.AssignedStatusVerificationMe == "assigned" {
-				filters = append(filters, "(submission_cache.active_assigned_verification_ids LIKE ?)")
-			}
-			data = append(data, utils.FormatLike(fmt.Sprintf("%d", uid)))
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.RequestedChangedStatusMe != nil {
-			if *filter.RequestedChangedStatusMe == "none" {
-				filters = append(filters, "(submission_cache.active_requested_changes_ids NOT LIKE ? OR submission_cache.active_requested_changes_ids IS NULL)")
-			} else if *filter.RequestedChangedStatusMe == "ongoing" {
-				filters = append(filters, "(submission_cache.active_requested_changes_ids LIKE ?)")
-			}
-			data = append(data, utils.FormatLike(fmt.Sprintf("%d", uid)))
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.ApprovalsStatusMe != nil {
-			if *filter.ApprovalsStatusMe == "no" {
-				filters = append(filters, "(submission_cache.active_approved_ids NOT LIKE ? OR submission_cache.active_approved_ids IS NULL)")
-			} else if *filter.ApprovalsStatusMe == "yes" {
-				filters = append(filters, "(submission_cache.active_approved_ids LIKE ?)")
-			}
-			data = append(data, utils.FormatLike(fmt.Sprintf("%d", uid)))
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.VerificationStatusMe != nil {
-			if *filter.VerificationStatusMe == "no" {
-				filters = append(filters, "(submission_cache.active_verified_ids NOT LIKE ? OR submission_cache.active_verified_ids IS NULL)")
-			} else if *filter.VerificationStatusMe == "yes" {
-				filters = append(filters, "(submission_cache.active_verified_ids LIKE ?)")
-			}
-			data = append(data, utils.FormatLike(fmt.Sprintf("%d", uid)))
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
+	return d.searchSubmissions(dbs, filter, true)
+}
 
-		if filter.AssignedStatusTestingUser != nil {
-			if *filter.AssignedStatusTestingUser == "unassigned" {
-				filters = append(filters, "(submission_cache.active_assigned_testing_ids NOT LIKE ? OR submission_cache.active_assigned_testing_ids IS NULL)")
-			} else if *filter.AssignedStatusTestingUser == "assigned" {
-				filters = append(filters, "(submission_cache.active_assigned_testing_ids LIKE ?)")
-			}
-			data = append(data, utils.FormatLike(fmt.Sprintf("%d", *filter.AssignedStatusUserID)))
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.AssignedStatusVerificationUser != nil {
-			if *filter.AssignedStatusVerificationUser == "unassigned" {
-				filters = append(filters, "(submission_cache.active_assigned_verification_ids NOT LIKE ? OR submission_cache.active_assigned_verification_ids IS NULL)")
-			} else if *filter.AssignedStatusVerificationUser == "assigned" {
-				filters = append(filters, "(submission_cache.active_assigned_verification_ids LIKE ?)")
-			}
-			data = append(data, utils.FormatLike(fmt.Sprintf("%d", *filter.AssignedStatusUserID)))
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.RequestedChangedStatusUser != nil {
-			if *filter.RequestedChangedStatusUser == "none" {
-				filters = append(filters, "(submission_cache.active_requested_changes_ids NOT LIKE ? OR submission_cache.active_requested_changes_ids IS NULL)")
-			} else if *filter.RequestedChangedStatusUser == "ongoing" {
-				filters = append(filters, "(submission_cache.active_requested_changes_ids LIKE ?)")
-			}
-			data = append(data, utils.FormatLike(fmt.Sprintf("%d", *filter.AssignedStatusUserID)))
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.ApprovalsStatusUser != nil {
-			if *filter.ApprovalsStatusUser == "no" {
-				filters = append(filters, "(submission_cache.active_approved_ids NOT LIKE ? OR submission_cache.active_approved_ids IS NULL)")
-			} else if *filter.ApprovalsStatusUser == "yes" {
-				filters = append(filters, "(submission_cache.active_approved_ids LIKE ?)")
-			}
-			data = append(data, utils.FormatLike(fmt.Sprintf("%d", *filter.AssignedStatusUserID)))
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.VerificationStatusUser != nil {
-			if *filter.VerificationStatusUser == "no" {
-				filters = append(filters, "(submission_cache.active_verified_ids NOT LIKE ? OR submission_cache.active_verified_ids IS NULL)")
-			} else if *filter.VerificationStatusUser == "yes" {
-				filters = append(filters, "(submission_cache.active_verified_ids LIKE ?)")
-			}
-			data = append(data, utils.FormatLike(fmt.Sprintf("%d", *filter.AssignedStatusUserID)))
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
+// searchSubmissions is SearchSubmissions which only counts all matching submissions if withCount is set, the count is -1 otherwise
+func (d *mysqlDAL) searchSubmissions(dbs DBSession, filter *types.SubmissionsFilter, withCount bool) ([]*types.ExtendedSubmission, int64, error) {
+	uid := utils.UserID(dbs.Ctx()) // TODO this should be passed as param
 
-		if filter.IsExtreme != nil {
-			filters = append(filters, "(meta.extreme = ?)")
-			data = append(data, *filter.IsExtreme)
-			masterFilters = append(masterFilters, "(extreme = ?)")
-			masterData = append(masterData, *filter.IsExtreme)
-		}
-		if len(filter.DistinctActions) != 0 {
-			filters = append(filters, `(REGEXP_LIKE (submission_cache.distinct_actions, CONCAT(CONCAT(?)`+strings.Repeat(", '|', CONCAT(?)", len(filter.DistinctActions)-1)+`)))`)
-			for _, da := range filter.DistinctActions {
-				data = append(data, da)
-			}
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if len(filter.DistinctActionsNot) != 0 {
-			filters = append(filters, `(NOT REGEXP_LIKE (submission_cache.distinct_actions, CONCAT(CONCAT(?)`+strings.Repeat(", '|', CONCAT(?)", len(filter.DistinctActionsNot)-1)+`)))`)
-			for _, da := range filter.DistinctActionsNot {
-				data = append(data, da)
-			}
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.LaunchCommandFuzzy != nil { // TODO not really fuzzy is it
-			filters = append(filters, "(meta.launch_command LIKE ?)")
-			data = append(data, utils.FormatLike(*filter.LaunchCommandFuzzy))
-			masterFilters = append(masterFilters, "(launch_command LIKE ?)")
-			masterData = append(masterData, utils.FormatLike(*filter.LaunchCommandFuzzy))
-		}
-		if filter.LastUploaderNotMe != nil {
-			if *filter.LastUploaderNotMe == "yes" {
-				filters = append(filters, "(uploader.id != ?)")
-				data = append(data, uid)
-			}
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.OrderBy != nil {
-			if *filter.OrderBy == "uploaded" {
-				currentOrderBy = "created_at"
-			} else if *filter.OrderBy == "updated" {
-				currentOrderBy = "updated_at"
-			} else if *filter.OrderBy == "size" {
-				currentOrderBy = "newest_file_size"
-			}
-		}
-		if filter.AscDesc != nil {
-			if *filter.AscDesc == "asc" {
-				currentSortOrder = "ASC"
-			} else if *filter.AscDesc == "desc" {
-				currentSortOrder = "DESC"
-			}
-		}
-		if filter.SubscribedMe != nil {
-			if *filter.SubscribedMe == "yes" {
-				filters = append(filters, "(sns.fk_user_id = ?)")
-			}
-			data = append(data, uid)
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-		if filter.ExcludeLegacy {
-			masterFilters = append(masterFilters, "(1 = 0)") // exclude legacy results
-		}
-	}
+	q := buildSubmissionSearchQuery(filter, uid)
 
 	and := ""
-	if len(filters) > 0 {
+	if len(q.filters) > 0 {
 		and = " AND "
 	}
 
 	masterAnd := ""
-	if len(masterFilters) > 0 {
+	if len(q.masterFilters) > 0 {
 		masterAnd = " AND "
 	}
 
@@ -343,10 +42,10 @@ func (d *mysqlDAL) SearchSubmissions(dbs DBSession, filter *types.SubmissionsFil
 			WHERE id = submission.fk_submission_level_id
 		) AS submission_level,
 		uploader.id AS uploader_id,
-		uploader.username AS uploader_username,
+		COALESCE(uploader.display_name, uploader.username) AS uploader_username,
 		uploader.avatar AS uploader_avatar,
 		updater.id AS updater_id,
-		updater.username AS updater_username,
+		COALESCE(updater.display_name, updater.username) AS updater_username,
 		updater.avatar AS updater_avatar,
 		newest_file.id AS newest_file_id,
 		newest_file.original_filename AS newest_file_original_filename,
@@ -368,7 +67,16 @@ func (d *mysqlDAL) SearchSubmissions(dbs DBSession, filter *types.SubmissionsFil
 		submission_cache.active_requested_changes_ids AS active_requested_changes_ids,
 		submission_cache.active_approved_ids AS active_approved_ids,
 		submission_cache.active_verified_ids AS active_verified_ids,
-		submission_cache.distinct_actions AS distinct_actions
+		submission_cache.distinct_actions AS distinct_actions,
+		submission.review_deadline AS review_deadline,
+		submission.priority AS priority,
+		submission.accepted_at AS accepted_at,
+		submission.accepted_game_id AS accepted_game_id,
+		submission.view_count AS view_count,
+		COALESCE(submission.fk_owner_id, uploader.id) AS owner_id,
+		submission.is_draft AS is_draft,
+		submission.status AS status,
+		GREATEST(COALESCE(newest_comment.created_at, 0), COALESCE(newest_file.created_at, 0)) AS last_activity_at
 		FROM submission
 		LEFT JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
 		LEFT JOIN submission_file AS oldest_file ON oldest_file.id = submission_cache.fk_oldest_file_id
@@ -455,7 +163,7 @@ func (d *mysqlDAL) SearchSubmissions(dbs DBSession, filter *types.SubmissionsFil
 	}
 
 	rest := ` LEFT JOIN submission_notification_subscription AS sns ON sns.fk_submission_id = submission.id
-		WHERE submission.deleted_at IS NULL` + and + strings.Join(filters, " AND ") + `
+		WHERE submission.deleted_at IS NULL` + and + strings.Join(q.filters, " AND ") + `
 		GROUP BY submission.id
 		UNION
 			SELECT -1 AS submission_id,
@@ -486,31 +194,45 @@ func (d *mysqlDAL) SearchSubmissions(dbs DBSession, filter *types.SubmissionsFil
 			(SELECT "") AS active_requested_changes_ids,
 			(SELECT "") AS active_approved_ids,
 			(SELECT "") AS active_verified_ids,
-			(SELECT "mark-added") AS distinct_actions
+			(SELECT "mark-added") AS distinct_actions,
+			(SELECT NULL) AS review_deadline,
+			(SELECT 0) AS priority,
+			(SELECT NULL) AS accepted_at,
+			(SELECT NULL) AS accepted_game_id,
+			(SELECT 0) AS view_count,
+			(SELECT -1) AS owner_id,
+			(SELECT FALSE) AS is_draft,
+			(SELECT "accepted") AS status,
+			date_modified AS last_activity_at
 			FROM masterdb_game
-			WHERE (SELECT 1) ` + masterAnd + strings.Join(masterFilters, " AND ") + `
-		ORDER BY ` + currentOrderBy + ` ` + currentSortOrder + `
+			WHERE (SELECT 1) ` + masterAnd + strings.Join(q.masterFilters, " AND ") + `
+		ORDER BY ` + q.orderBy + ` ` + q.sortOrder + `
 		`
 	unlimitedQuery := finalQuery + rest
 	finalQuery = unlimitedQuery + ` LIMIT ? OFFSET ?`
 
 	finalData := make([]interface{}, 0)
-	finalData = append(finalData, data...)
-	unlimitedData := append(finalData, masterData...)
-	finalData = append(unlimitedData, currentLimit, currentOffset)
+	finalData = append(finalData, q.joinData...)
+	finalData = append(finalData, q.data...)
+	unlimitedData := append(finalData, q.masterData...)
+	finalData = append(unlimitedData, q.limit, q.offset)
 
 	countingQuery := `SELECT COUNT(*) FROM ( ` + unlimitedQuery + ` ) AS counterino`
-	var counter int64
+	var counter int64 = -1
 	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		row := d.db.QueryRowContext(dbs.Ctx(), countingQuery, unlimitedData...)
-		if err := row.Scan(&counter); err != nil {
-			counter = -1
-			return
-		}
-	}()
+	if withCount {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			row := d.db.QueryRowContext(dbs.Ctx(), countingQuery, unlimitedData...)
+			d.slowQueries.observe(countingQuery, len(unlimitedData), start)
+			if err := row.Scan(&counter); err != nil {
+				counter = -1
+				return
+			}
+		}()
+	}
 
 	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), finalQuery, finalData...)
 	if err != nil {
@@ -530,6 +252,9 @@ func (d *mysqlDAL) SearchSubmissions(dbs DBSession, filter *types.SubmissionsFil
 	var approvedUserIDs *string
 	var verifiedUserIDs *string
 	var distinctActions *string
+	var reviewDeadline *int64
+	var acceptedAt *int64
+	var lastActivityAt int64
 
 	for rows.Next() {
 		s := &types.ExtendedSubmission{}
@@ -544,13 +269,33 @@ func (d *mysqlDAL) SearchSubmissions(dbs DBSession, filter *types.SubmissionsFil
 			&s.BotAction,
 			&s.FileCount,
 			&assignedTestingUserIDs, &assignedVerificationUserIDs, &requestedChangesUserIDs, &approvedUserIDs, &verifiedUserIDs,
-			&distinctActions); err != nil {
+			&distinctActions,
+			&reviewDeadline,
+			&s.Priority,
+			&acceptedAt, &s.AcceptedGameID,
+			&s.ViewCount,
+			&s.OwnerID,
+			&s.IsDraft,
+			&s.Status,
+			&lastActivityAt); err != nil {
 			return nil, 0, err
 		}
 		s.SubmitterAvatarURL = utils.FormatAvatarURL(s.SubmitterID, submitterAvatar)
 		s.UpdaterAvatarURL = utils.FormatAvatarURL(s.UpdaterID, updaterAvatar)
 		s.UploadedAt = time.Unix(uploadedAt, 0)
 		s.UpdatedAt = time.Unix(updatedAt, 0)
+		s.LastActivityAt = time.Unix(lastActivityAt, 0)
+		s.IsExtreme = s.CurationExtreme != nil && strings.EqualFold(*s.CurationExtreme, "yes")
+		s.ReviewDeadline = nil
+		if reviewDeadline != nil {
+			rd := time.Unix(*reviewDeadline, 0)
+			s.ReviewDeadline = &rd
+		}
+		s.AcceptedAt = nil
+		if acceptedAt != nil {
+			aa := time.Unix(*acceptedAt, 0)
+			s.AcceptedAt = &aa
+		}
 
 		s.AssignedTestingUserIDs = []int64{}
 		if assignedTestingUserIDs != nil && len(*assignedTestingUserIDs) > 0 {
@@ -626,6 +371,620 @@ func (d *mysqlDAL) SearchSubmissions(dbs DBSession, filter *types.SubmissionsFil
 	return result, counter, nil
 }
 
+// SearchSubmissionsByTag returns extended submissions whose newest file's curation meta contains the exact given tag
+func (d *mysqlDAL) SearchSubmissionsByTag(dbs DBSession, tag string, filter *types.SubmissionsFilter) ([]*types.ExtendedSubmission, int64, error) {
+	f := &types.SubmissionsFilter{}
+	if filter != nil {
+		*f = *filter
+	}
+	f.Tag = &tag
+
+	return d.SearchSubmissions(dbs, f)
+}
+
+// GetSubmissionsByDeauthorizedUsers returns submissions whose submitters do not hold any discord role anymore, narrowed and paginated by the filter
+func (d *mysqlDAL) GetSubmissionsByDeauthorizedUsers(dbs DBSession, filter *types.SubmissionsFilter) ([]*types.ExtendedSubmission, int64, error) {
+	f := &types.SubmissionsFilter{}
+	if filter != nil {
+		*f = *filter
+	}
+	f.SubmitterDeauthorized = true
+
+	return d.SearchSubmissions(dbs, f)
+}
+
+// GetSubmissionsByLanguage returns submissions whose newest file lists a given language code, narrowed and paginated by the filter,
+// see utils.SplitCurationLanguages. The language code overrides any language set in the filter.
+func (d *mysqlDAL) GetSubmissionsByLanguage(dbs DBSession, code string, filter *types.SubmissionsFilter) ([]*types.ExtendedSubmission, int64, error) {
+	f := &types.SubmissionsFilter{}
+	if filter != nil {
+		*f = *filter
+	}
+	code = strings.ToLower(strings.TrimSpace(code))
+	f.Language = &code
+
+	return d.SearchSubmissions(dbs, f)
+}
+
+// GetSubmissionsReadyForAcceptance returns submissions which passed every review gate, highest priority first:
+// approved by the validator bot, approved and verified by staff with no changes requested since, and with complete curation meta.
+// Each gate is a plain search filter, so a gate can be dropped or added here without touching the query.
+func (d *mysqlDAL) GetSubmissionsReadyForAcceptance(dbs DBSession) ([]*types.ExtendedSubmission, error) {
+	const maxResults = 100
+
+	limit := int64(maxResults)
+	orderBy := "priority"
+	approved := "approved"
+	verified := "verified"
+	noChangesRequested := "none"
+	submissions, _, err := d.SearchSubmissions(dbs, &types.SubmissionsFilter{
+		BotActions:             []string{constants.ActionApprove},
+		Status:                 []string{constants.SubmissionStatusApproved},
+		ApprovalsStatus:        &approved,
+		VerificationStatus:     &verified,
+		RequestedChangedStatus: &noChangesRequested,
+		MetaComplete:           true,
+		OrderBy:                &orderBy,
+		ResultsPerPage:         &limit,
+		ExcludeLegacy:          true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return submissions, nil
+}
+
+// GetRandomSubmissions returns a random sample of up to count submissions matching the filter, ignoring its ordering and pagination.
+// MySQL has to sort every matching row to pick the sample, so count is capped and narrow filters are preferable.
+func (d *mysqlDAL) GetRandomSubmissions(dbs DBSession, count int, filter *types.SubmissionsFilter) ([]*types.ExtendedSubmission, error) {
+	const maxCount = 50
+	if count < 1 || count > maxCount {
+		count = maxCount
+	}
+
+	f := &types.SubmissionsFilter{}
+	if filter != nil {
+		*f = *filter
+	}
+	limit := int64(count)
+	orderBy := "random"
+	f.OrderBy = &orderBy
+	f.AscDesc = nil
+	f.ResultsPerPage = &limit
+	f.Page = nil
+	f.AfterUpdatedAt = nil
+	f.AfterID = nil
+
+	submissions, _, err := d.SearchSubmissions(dbs, f)
+	return submissions, err
+}
+
+// FindSubmissionsByNormalizedTitle returns submissions with titles similar to the given one, closest matches first.
+// Titles are compared in their normalized form, see utils.NormalizeTitle.
+func (d *mysqlDAL) FindSubmissionsByNormalizedTitle(dbs DBSession, title string) ([]*types.ExtendedSubmission, error) {
+	const maxResults = 20
+	const minPrefixLength = 3
+
+	normalized := []rune(utils.NormalizeTitle(title))
+	if len(normalized) == 0 {
+		return []*types.ExtendedSubmission{}, nil
+	}
+
+	// match on a prefix of the normalized title so the lookup can use the index and still catch near matches
+	prefixLength := len(normalized) / 2
+	if prefixLength < minPrefixLength {
+		prefixLength = minPrefixLength
+	}
+	if prefixLength > len(normalized) {
+		prefixLength = len(normalized)
+	}
+	prefix := string(normalized[:prefixLength])
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT submission.id FROM submission
+		JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+		JOIN curation_meta meta ON meta.fk_submission_file_id = submission_cache.fk_newest_file_id
+		WHERE submission.deleted_at IS NULL
+		AND meta.normalized_title LIKE ?
+		ORDER BY meta.normalized_title = ? DESC, ABS(CHAR_LENGTH(meta.normalized_title) - ?), submission.id DESC
+		LIMIT ?`,
+		prefix+"%", string(normalized), len(normalized), maxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sids := make([]int64, 0, maxResults)
+	for rows.Next() {
+		var sid int64
+		if err := rows.Scan(&sid); err != nil {
+			return nil, err
+		}
+		sids = append(sids, sid)
+	}
+
+	if len(sids) == 0 {
+		return []*types.ExtendedSubmission{}, nil
+	}
+
+	// keep the similarity ordering
+	return d.GetExtendedSubmissionsByIDs(dbs, sids)
+}
+
+// GetExtendedSubmissionsByIDs returns submissions in the order of given IDs, skipping IDs which are not found.
+// Repeated IDs are returned once, at their first position.
+func (d *mysqlDAL) GetExtendedSubmissionsByIDs(dbs DBSession, sids []int64) ([]*types.ExtendedSubmission, error) {
+	if len(sids) == 0 {
+		return []*types.ExtendedSubmission{}, nil
+	}
+
+	limit := int64(len(sids))
+	submissions, _, err := d.searchSubmissions(dbs, &types.SubmissionsFilter{SubmissionIDs: sids, ResultsPerPage: &limit, ExcludeLegacy: true}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*types.ExtendedSubmission, len(submissions))
+	for _, s := range submissions {
+		byID[s.SubmissionID] = s
+	}
+	result := make([]*types.ExtendedSubmission, 0, len(submissions))
+	for _, sid := range sids {
+		if s, ok := byID[sid]; ok {
+			result = append(result, s)
+			delete(byID, sid)
+		}
+	}
+
+	return result, nil
+}
+
+// GetSubmissionDetail returns a submission together with the meta and images of its newest file and its comments of a given visibility,
+// empty visibility means all comments. Returns sql.ErrNoRows if the submission does not exist.
+// It takes three queries: the submission without counting, the meta with its images, and the comments.
+func (d *mysqlDAL) GetSubmissionDetail(dbs DBSession, sid int64, visibility string) (*types.SubmissionDetail, error) {
+	submissions, err := d.GetExtendedSubmissionsByIDs(dbs, []int64{sid})
+	if err != nil {
+		return nil, err
+	}
+	if len(submissions) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	detail := &types.SubmissionDetail{Submission: submissions[0]}
+
+	detail.CurationMeta, detail.CurationImages, err = d.getCurationMetaWithImages(dbs, detail.Submission.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail.Comments, err = d.GetExtendedCommentsBySubmissionID(dbs, sid, visibility)
+	if err != nil {
+		return nil, err
+	}
+
+	return detail, nil
+}
+
+// getCurationMetaWithImages returns curation meta and images of a submission file in one query, meta is nil when the file has none.
+// Images of a file without meta take a second query.
+func (d *mysqlDAL) getCurationMetaWithImages(dbs DBSession, sfid int64) (*types.CurationMeta, []*types.CurationImage, error) {
+	row := dbs.Tx().QueryRowContext(dbs.Ctx(), `SELECT `+curationMetaColumns+`,
+		(SELECT JSON_ARRAYAGG(JSON_OBJECT('id', curation_image.id, 'type', curation_image_type.name, 'filename', curation_image.filename))
+		 FROM curation_image
+		 JOIN curation_image_type ON curation_image_type.id = curation_image.fk_curation_image_type_id
+		 WHERE curation_image.fk_submission_file_id = curation_meta.fk_submission_file_id)
+		FROM curation_meta JOIN submission_file ON curation_meta.fk_submission_file_id = submission_file.id
+		WHERE fk_submission_file_id=? AND submission_file.deleted_at IS NULL`, sfid)
+
+	meta := &types.CurationMeta{}
+	var imagesJSON *string
+	if err := scanCurationMeta(row, meta, &imagesJSON); err != nil {
+		if err != sql.ErrNoRows {
+			return nil, nil, err
+		}
+		images, err := d.GetCurationImagesBySubmissionFileID(dbs, sfid)
+		return nil, images, err
+	}
+
+	images := make([]*types.CurationImage, 0)
+	if imagesJSON != nil {
+		var raw []struct {
+			ID       int64  `json:"id"`
+			Type     string `json:"type"`
+			Filename string `json:"filename"`
+		}
+		if err := json.Unmarshal([]byte(*imagesJSON), &raw); err != nil {
+			return nil, nil, err
+		}
+		for _, r := range raw {
+			images = append(images, &types.CurationImage{ID: r.ID, SubmissionFileID: sfid, Type: r.Type, Filename: r.Filename})
+		}
+	}
+
+	return meta, images, nil
+}
+
+// FindExistingTitles returns for each given title whether a submission with the same normalized title exists, see utils.NormalizeTitle
+func (d *mysqlDAL) FindExistingTitles(dbs DBSession, titles []string) (map[string]bool, error) {
+	result := make(map[string]bool, len(titles))
+	if len(titles) == 0 {
+		return result, nil
+	}
+
+	normalizedTitles := make(map[string][]string, len(titles))
+	params := make([]interface{}, 0, len(titles))
+	for _, title := range titles {
+		result[title] = false
+		normalized := utils.NormalizeTitle(title)
+		if len(normalized) == 0 {
+			continue
+		}
+		if _, ok := normalizedTitles[normalized]; !ok {
+			params = append(params, normalized)
+		}
+		normalizedTitles[normalized] = append(normalizedTitles[normalized], title)
+	}
+
+	if len(params) == 0 {
+		return result, nil
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT DISTINCT meta.normalized_title FROM submission
+		JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+		JOIN curation_meta meta ON meta.fk_submission_file_id = submission_cache.fk_newest_file_id
+		WHERE submission.deleted_at IS NULL
+		AND meta.normalized_title IN (?`+strings.Repeat(",?", len(params)-1)+`)`,
+		params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var normalized string
+		if err := rows.Scan(&normalized); err != nil {
+			return nil, err
+		}
+		for _, title := range normalizedTitles[normalized] {
+			result[title] = true
+		}
+	}
+
+	return result, nil
+}
+
+// GetRecentlyActiveSubmissions returns submissions with the most recent file or comment, limit is capped to a sane maximum
+func (d *mysqlDAL) GetRecentlyActiveSubmissions(dbs DBSession, limit int) ([]*types.ExtendedSubmission, error) {
+	const maxLimit = 100
+	if limit < 1 || limit > maxLimit {
+		limit = maxLimit
+	}
+
+	l := int64(limit)
+	orderBy := "activity"
+	submissions, _, err := d.SearchSubmissions(dbs, &types.SubmissionsFilter{ResultsPerPage: &l, OrderBy: &orderBy, ExcludeLegacy: true})
+	if err != nil {
+		return nil, err
+	}
+
+	return submissions, nil
+}
+
+// GetSubmissionsGroupedBySubmitter returns submissions of given users keyed by user ID, oldest upload first.
+// Submissions count towards their owner, every given user has an entry even if they have no submissions.
+func (d *mysqlDAL) GetSubmissionsGroupedBySubmitter(dbs DBSession, uids []int64) (map[int64][]*types.ExtendedSubmission, error) {
+	// exports want the whole history, the limit only guards against runaway queries
+	const maxResults = 1000000
+
+	result := make(map[int64][]*types.ExtendedSubmission, len(uids))
+	for _, uid := range uids {
+		result[uid] = []*types.ExtendedSubmission{}
+	}
+	if len(uids) == 0 {
+		return result, nil
+	}
+
+	limit := int64(maxResults)
+	orderBy := "uploaded"
+	ascDesc := "asc"
+	submissions, _, err := d.SearchSubmissions(dbs, &types.SubmissionsFilter{
+		SubmitterIDs:   uids,
+		OrderBy:        &orderBy,
+		AscDesc:        &ascDesc,
+		ResultsPerPage: &limit,
+		ExcludeLegacy:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range submissions {
+		result[s.OwnerID] = append(result[s.OwnerID], s)
+	}
+
+	return result, nil
+}
+
+// GetSubmissionsWithDisallowedFiles returns submissions having a file whose original or current filename extension is not in the allowlist.
+// Extensions are compared case-insensitively with or without the leading dot, files with no extension are reported as well.
+func (d *mysqlDAL) GetSubmissionsWithDisallowedFiles(dbs DBSession, allowedExtensions []string) ([]*types.ExtendedSubmission, error) {
+	allowed := make([]interface{}, 0, len(allowedExtensions))
+	for _, ext := range allowedExtensions {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if len(ext) > 0 {
+			allowed = append(allowed, ext)
+		}
+	}
+
+	disallowed := func(column string) string {
+		if len(allowed) == 0 {
+			return `TRUE`
+		}
+		return `(LOCATE('.', ` + column + `) = 0 OR LOWER(SUBSTRING_INDEX(` + column + `, '.', -1)) NOT IN (?` + strings.Repeat(",?", len(allowed)-1) + `))`
+	}
+	args := append(append([]interface{}{}, allowed...), allowed...)
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT DISTINCT submission.id FROM submission
+		JOIN submission_file ON submission_file.fk_submission_id = submission.id
+		WHERE submission.deleted_at IS NULL AND submission_file.deleted_at IS NULL
+		AND (`+disallowed("submission_file.original_filename")+` OR `+disallowed("submission_file.current_filename")+`)
+		ORDER BY submission.id`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sids := make([]int64, 0)
+	for rows.Next() {
+		var sid int64
+		if err := rows.Scan(&sid); err != nil {
+			return nil, err
+		}
+		sids = append(sids, sid)
+	}
+
+	return d.GetExtendedSubmissionsByIDs(dbs, sids)
+}
+
+// GetOldestUnreviewedSubmissions returns submissions nobody but the bots acted on since upload, oldest upload first.
+// Drafts and deleted submissions are skipped, limit is capped to a sane maximum.
+func (d *mysqlDAL) GetOldestUnreviewedSubmissions(dbs DBSession, limit int) ([]*types.ExtendedSubmission, error) {
+	const maxLimit = 100
+	if limit < 1 || limit > maxLimit {
+		limit = maxLimit
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT submission.id FROM submission
+		JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+		JOIN submission_file AS oldest_file ON oldest_file.id = submission_cache.fk_oldest_file_id
+		WHERE submission.deleted_at IS NULL AND submission.is_draft = FALSE
+		AND NOT EXISTS (
+			SELECT 1 FROM comment
+			JOIN action ON action.id = comment.fk_action_id
+			WHERE comment.fk_submission_id = submission.id AND comment.deleted_at IS NULL
+			AND comment.fk_user_id NOT IN (?, ?) AND action.name != ?)
+		ORDER BY oldest_file.created_at, submission.id
+		LIMIT ?`,
+		constants.ValidatorID, constants.SystemID, constants.ActionUpload, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sids := make([]int64, 0, limit)
+	for rows.Next() {
+		var sid int64
+		if err := rows.Scan(&sid); err != nil {
+			return nil, err
+		}
+		sids = append(sids, sid)
+	}
+
+	return d.GetExtendedSubmissionsByIDs(dbs, sids)
+}
+
+// GetSubmissionsWithBotAction returns up to 1000 submissions whose latest bot action is the given one
+func (d *mysqlDAL) GetSubmissionsWithBotAction(dbs DBSession, action string) ([]*types.ExtendedSubmission, error) {
+	const maxResults = 1000
+
+	limit := int64(maxResults)
+	submissions, _, err := d.searchSubmissions(dbs, &types.SubmissionsFilter{
+		BotActions:     []string{action},
+		ResultsPerPage: &limit,
+		ExcludeLegacy:  true,
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return submissions, nil
+}
+
+// GetSubmissionsBySource returns all submissions whose newest curation meta has the same source, compared in normalized form.
+// Sources which are not valid URLs are matched exactly.
+func (d *mysqlDAL) GetSubmissionsBySource(dbs DBSession, sourceURL string) ([]*types.ExtendedSubmission, error) {
+	normalized := utils.NormalizeSourceURL(sourceURL)
+	if len(normalized) == 0 {
+		return []*types.ExtendedSubmission{}, nil
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT submission.id FROM submission
+		JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+		JOIN curation_meta meta ON meta.fk_submission_file_id = submission_cache.fk_newest_file_id
+		LEFT JOIN comment AS newest_comment ON newest_comment.id = submission_cache.fk_newest_comment_id
+		WHERE submission.deleted_at IS NULL
+		AND meta.normalized_source = ?
+		ORDER BY newest_comment.created_at DESC, submission.id DESC`,
+		normalized)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sids := make([]int64, 0)
+	for rows.Next() {
+		var sid int64
+		if err := rows.Scan(&sid); err != nil {
+			return nil, err
+		}
+		sids = append(sids, sid)
+	}
+
+	return d.GetExtendedSubmissionsByIDs(dbs, sids)
+}
+
+// FindSubmissionsByLaunchCommand returns submissions whose newest curation meta has the same launch command, ignoring surrounding whitespace.
+// Empty launch commands never match.
+func (d *mysqlDAL) FindSubmissionsByLaunchCommand(dbs DBSession, launchCommand string) ([]*types.ExtendedSubmission, error) {
+	normalized := strings.TrimSpace(launchCommand)
+	if len(normalized) == 0 {
+		return []*types.ExtendedSubmission{}, nil
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT submission.id FROM submission
+		JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+		JOIN curation_meta meta ON meta.fk_submission_file_id = submission_cache.fk_newest_file_id
+		LEFT JOIN comment AS newest_comment ON newest_comment.id = submission_cache.fk_newest_comment_id
+		WHERE submission.deleted_at IS NULL
+		AND meta.normalized_launch_command = ?
+		ORDER BY newest_comment.created_at DESC, submission.id DESC`,
+		normalized)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sids := make([]int64, 0)
+	for rows.Next() {
+		var sid int64
+		if err := rows.Scan(&sid); err != nil {
+			return nil, err
+		}
+		sids = append(sids, sid)
+	}
+
+	return d.GetExtendedSubmissionsByIDs(dbs, sids)
+}
+
+// curationMetaFields lists curation_meta columns which can be checked for completeness
+var curationMetaFields = map[string]bool{
+	"application_path":     true,
+	"developer":            true,
+	"extreme":              true,
+	"game_notes":           true,
+	"languages":            true,
+	"launch_command":       true,
+	"original_description": true,
+	"play_mode":            true,
+	"platform":             true,
+	"publisher":            true,
+	"release_date":         true,
+	"series":               true,
+	"source":               true,
+	"status":               true,
+	"tags":                 true,
+	"tag_categories":       true,
+	"title":                true,
+	"alternate_titles":     true,
+	"library":              true,
+	"version":              true,
+	"curation_notes":       true,
+	"mount_parameters":     true,
+}
+
+// GetSubmissionsWithIncompleteMeta returns submissions whose newest file's meta has any of the required fields empty.
+// Submissions without any meta are always considered incomplete.
+func (d *mysqlDAL) GetSubmissionsWithIncompleteMeta(dbs DBSession, requiredFields []string) ([]*types.ExtendedSubmission, error) {
+	conditions := []string{"meta.id IS NULL"}
+	for _, field := range requiredFields {
+		if !curationMetaFields[field] {
+			return nil, fmt.Errorf(constants.ErrorInvalidCurationMetaField)
+		}
+		conditions = append(conditions, fmt.Sprintf("TRIM(COALESCE(meta.%s, '')) = ''", field))
+	}
+
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT submission.id FROM submission
+		JOIN submission_cache ON submission_cache.fk_submission_id = submission.id
+		LEFT JOIN curation_meta meta ON meta.fk_submission_file_id = submission_cache.fk_newest_file_id
+		LEFT JOIN comment AS newest_comment ON newest_comment.id = submission_cache.fk_newest_comment_id
+		WHERE submission.deleted_at IS NULL
+		AND (`+strings.Join(conditions, " OR ")+`)
+		ORDER BY newest_comment.created_at DESC, submission.id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sids := make([]int64, 0)
+	for rows.Next() {
+		var sid int64
+		if err := rows.Scan(&sid); err != nil {
+			return nil, err
+		}
+		sids = append(sids, sid)
+	}
+
+	return d.GetExtendedSubmissionsByIDs(dbs, sids)
+}
+
+// GetSubmissionsWithMetaConflicts returns submissions whose files have curation metas differing in title or platform, newest submissions first.
+// Empty and missing values count as a value of their own.
+func (d *mysqlDAL) GetSubmissionsWithMetaConflicts(dbs DBSession) ([]*types.SubmissionMetaConflict, error) {
+	rows, err := dbs.Tx().QueryContext(dbs.Ctx(), `
+		SELECT submission.id,
+			COUNT(DISTINCT COALESCE(meta.title, '')) > 1 AS title_conflict,
+			COUNT(DISTINCT COALESCE(meta.platform, '')) > 1 AS platform_conflict
+		FROM submission
+		JOIN submission_file ON submission_file.fk_submission_id = submission.id
+		JOIN curation_meta meta ON meta.fk_submission_file_id = submission_file.id
+		WHERE submission.deleted_at IS NULL
+		AND submission_file.deleted_at IS NULL
+		GROUP BY submission.id
+		HAVING title_conflict OR platform_conflict
+		ORDER BY submission.id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sids := make([]int64, 0)
+	fields := make(map[int64][]string)
+	for rows.Next() {
+		var sid int64
+		var titleConflict, platformConflict bool
+		if err := rows.Scan(&sid, &titleConflict, &platformConflict); err != nil {
+			return nil, err
+		}
+		sids = append(sids, sid)
+		if titleConflict {
+			fields[sid] = append(fields[sid], "title")
+		}
+		if platformConflict {
+			fields[sid] = append(fields[sid], "platform")
+		}
+	}
+
+	submissions, err := d.GetExtendedSubmissionsByIDs(dbs, sids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.SubmissionMetaConflict, 0, len(submissions))
+	for _, s := range submissions {
+		result = append(result, &types.SubmissionMetaConflict{Submission: s, ConflictingFields: fields[s.SubmissionID]})
+	}
+
+	return result, nil
+}
+
 func addMultifilter(tableName string, masterTableName *string, filterContents string, filters, masterFilters []string, data, masterData []interface{}) ([]string, []string, []interface{}, []interface{}) {
 	substrings := strings.Split(filterContents, ",")
 	trimmed := make([]string, 0, len(substrings))
@@ -834,7 +1193,7 @@ func (d *mysqlDAL) SearchFlashfreezeFiles(dbs DBSession, filter *types.Flashfree
 		SELECT 
        		file.id AS file_id,
 			file.fk_user_id AS uploader_id,
-			uploader.username AS uploader_username,
+			COALESCE(uploader.display_name, uploader.username) AS uploader_username,
 			file.original_filename AS original_filename,
 			file.md5sum AS md5sum,
 			file.sha256sum AS sha256sum,
@@ -877,7 +1236,7 @@ func (d *mysqlDAL) SearchFlashfreezeFiles(dbs DBSession, filter *types.Flashfree
 			SELECT
 			entry.fk_flashfreeze_file_id AS file_id,
 				(SELECT file.fk_user_id FROM flashfreeze_file file WHERE file.id = entry.fk_flashfreeze_file_id) AS uploader_id,
-				(SELECT uploader.username FROM flashfreeze_file file LEFT JOIN discord_user AS uploader ON uploader.id = file.fk_user_id WHERE file.id = entry.fk_flashfreeze_file_id) AS uploader_username,
+				(SELECT COALESCE(uploader.display_name, uploader.username) FROM flashfreeze_file file LEFT JOIN discord_user AS uploader ON uploader.id = file.fk_user_id WHERE file.id = entry.fk_flashfreeze_file_id) AS uploader_username,
 				entry.filename AS original_filename,
 				entry.md5sum AS md5sum,
 				entry.sha256sum AS sha256sum,
@@ -916,7 +1275,9 @@ func (d *mysqlDAL) SearchFlashfreezeFiles(dbs DBSession, filter *types.Flashfree
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
+		start := time.Now()
 		row := d.db.QueryRowContext(dbs.Ctx(), countingQuery, unlimitedData...)
+		d.slowQueries.observe(countingQuery, len(unlimitedData), start)
 		if err := row.Scan(&counter); err != nil {
 			counter = -1
 			return
@@ -1019,7 +1380,7 @@ func (d *mysqlDAL) SearchFixes(dbs DBSession, filter *types.FixesFilter) ([]*typ
 		    fixes.title AS title,
 		    fixes.description AS description,
 			fixes.fk_user_id AS uploader_id,
-			uploader.username AS uploader_username,
+			COALESCE(uploader.display_name, uploader.username) AS uploader_username,
 			fixes.created_at AS uploaded_at
 		FROM fixes
 			LEFT JOIN discord_user AS uploader ON uploader.id = fixes.fk_user_id `
@@ -1048,7 +1409,9 @@ func (d *mysqlDAL) SearchFixes(dbs DBSession, filter *types.FixesFilter) ([]*typ
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
+		start := time.Now()
 		row := d.db.QueryRowContext(dbs.Ctx(), countingQuery, unlimitedData...)
+		d.slowQueries.observe(countingQuery, len(unlimitedData), start)
 		if err := row.Scan(&counter); err != nil {
 			counter = -1
 			return
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+// slowQueryLogMaxLength is the maximum number of characters of a logged query
+const slowQueryLogMaxLength = 500
+
+// slowQueryLogger logs statements which run longer than a threshold, zero threshold disables it
+type slowQueryLogger struct {
+	l         *logrus.Entry
+	threshold time.Duration
+}
+
+// observe logs a statement started at a given time if it was too slow.
+// Arguments may hold secrets or user data, so only their count is logged, never their values.
+func (sq *slowQueryLogger) observe(query string, argCount int, start time.Time) {
+	if sq == nil || sq.threshold <= 0 {
+		return
+	}
+	duration := time.Since(start)
+	if duration < sq.threshold {
+		return
+	}
+	sq.l.WithFields(logrus.Fields{
+		"event":       "slow-query",
+		"duration_ns": duration.Nanoseconds(),
+		"query":       truncateQuery(query, slowQueryLogMaxLength),
+		"arg_count":   argCount,
+	}).Warn("slow query")
+}
+
+// truncateQuery collapses whitespace of a query and cuts it to at most maxLength characters
+func truncateQuery(query string, maxLength int) string {
+	q := []rune(strings.Join(strings.Fields(query), " "))
+	if len(q) <= maxLength {
+		return string(q)
+	}
+	return string(q[:maxLength]) + "…"
+}
+
+// Tx is a transaction which times its statements and logs the slow ones
+type Tx struct {
+	*sql.Tx
+	slowQueries *slowQueryLogger
+}
+
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer tx.slowQueries.observe(query, len(args), time.Now())
+	return tx.Tx.QueryContext(ctx, query, args...)
+}
+
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer tx.slowQueries.observe(query, len(args), time.Now())
+	return tx.Tx.QueryRowContext(ctx, query, args...)
+}
+
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer tx.slowQueries.observe(query, len(args), time.Now())
+	return tx.Tx.ExecContext(ctx, query, args...)
+}
+
+// timedStmt is a prepared statement which times its executions and logs the slow ones
+type timedStmt struct {
+	*sql.Stmt
+	query       string
+	slowQueries *slowQueryLogger
+}
+
+func (s *timedStmt) QueryContext(ctx context.Context, args ...interface{}) (*sql.Rows, error) {
+	defer s.slowQueries.observe(s.query, len(args), time.Now())
+	return s.Stmt.QueryContext(ctx, args...)
+}
+
+func (s *timedStmt) QueryRowContext(ctx context.Context, args ...interface{}) *sql.Row {
+	defer s.slowQueries.observe(s.query, len(args), time.Now())
+	return s.Stmt.QueryRowContext(ctx, args...)
+}
+
+func (s *timedStmt) ExecContext(ctx context.Context, args ...interface{}) (sql.Result, error) {
+	defer s.slowQueries.observe(s.query, len(args), time.Now())
+	return s.Stmt.ExecContext(ctx, args...)
+}
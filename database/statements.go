@@ -0,0 +1,73 @@
+package database
+
+import (
+	"database/sql"
+	"sync"
+)
+
+// statementCache lazily prepares static queries on the connection pool and keeps them for reuse.
+// Queries with dynamically built WHERE clauses must not go through here.
+type statementCache struct {
+	mutex      sync.Mutex
+	statements map[string]*sql.Stmt
+	closed     bool
+}
+
+func newStatementCache() *statementCache {
+	return &statementCache{
+		statements: make(map[string]*sql.Stmt),
+	}
+}
+
+// get returns a prepared statement for a given query, preparing it on first use
+func (sc *statementCache) get(db *sql.DB, dbs DBSession, query string) (*sql.Stmt, error) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	if sc.closed {
+		return nil, sql.ErrConnDone
+	}
+
+	if stmt, ok := sc.statements[query]; ok {
+		return stmt, nil
+	}
+
+	stmt, err := db.PrepareContext(dbs.Ctx(), query)
+	if err != nil {
+		return nil, err
+	}
+	sc.statements[query] = stmt
+
+	return stmt, nil
+}
+
+// close closes all prepared statements, the cache cannot be used afterwards
+func (sc *statementCache) close() error {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	var firstErr error
+	for query, stmt := range sc.statements {
+		if err := stmt.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(sc.statements, query)
+	}
+	sc.closed = true
+
+	return firstErr
+}
+
+// prepared returns a prepared statement for a given static query, bound to the session's transaction
+func (d *mysqlDAL) prepared(dbs DBSession, query string) (*timedStmt, error) {
+	stmt, err := d.statements.get(d.db, dbs, query)
+	if err != nil {
+		return nil, err
+	}
+	return &timedStmt{Stmt: dbs.Tx().StmtContext(dbs.Ctx(), stmt), query: query, slowQueries: d.slowQueries}, nil
+}
+
+// Close releases prepared statements held by the DAL
+func (d *mysqlDAL) Close() error {
+	return d.statements.close()
+}
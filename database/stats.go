@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"github.com/Dri0m/flashpoint-submission-system/utils"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+const statsLogInterval = 60 * time.Second
+
+// Stats returns connection pool statistics of the underlying database handle
+func (d *mysqlDAL) Stats() sql.DBStats {
+	return d.db.Stats()
+}
+
+// LogStats periodically logs connection pool statistics until the context is cancelled
+func (d *mysqlDAL) LogStats(l *logrus.Entry, ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer l.Infoln("db stats printer stopped")
+
+	bucket, ticker := utils.NewBucketLimiter(statsLogInterval, 1)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			l.Infoln("context cancelled, stopping db stats printer")
+			return
+		case <-bucket:
+			s := d.Stats()
+			l.WithFields(logrus.Fields{
+				"max_open":      s.MaxOpenConnections,
+				"open":          s.OpenConnections,
+				"in_use":        s.InUse,
+				"idle":          s.Idle,
+				"wait_count":    s.WaitCount,
+				"wait_duration": s.WaitDuration.String(),
+			}).Debug("db stats")
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"container/list"
+	"github.com/Dri0m/flashpoint-submission-system/types"
+	"sync"
+	"time"
+)
+
+const defaultDiscordUserCacheSize = 1024
+
+// defaultDiscordUserCacheTTL bounds how stale a cached user can get when it is changed outside of StoreDiscordUser,
+// e.g. by another instance sharing the database or by a transaction that stored the user and then rolled back.
+const defaultDiscordUserCacheTTL = 5 * time.Minute
+
+type discordUserCacheEntry struct {
+	user      types.DiscordUser
+	expiresAt time.Time
+}
+
+// discordUserCache is a size-bounded LRU cache of discord users with per-entry expiration, safe for concurrent use
+type discordUserCache struct {
+	mutex   sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List // front is the most recently used
+	entries map[int64]*list.Element
+}
+
+func newDiscordUserCache(size int, ttl time.Duration) *discordUserCache {
+	return &discordUserCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[int64]*list.Element),
+	}
+}
+
+// get returns a copy of a cached user, if present and not expired
+func (c *discordUserCache) get(uid int64) (*types.DiscordUser, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, ok := c.entries[uid]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*discordUserCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(e)
+		delete(c.entries, uid)
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+
+	user := entry.user
+	return &user, true
+}
+
+// put stores a copy of a user, evicting the least recently used users over capacity
+func (c *discordUserCache) put(user *types.DiscordUser) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.size <= 0 {
+		return
+	}
+
+	entry := &discordUserCacheEntry{user: *user, expiresAt: time.Now().Add(c.ttl)}
+	if e, ok := c.entries[user.ID]; ok {
+		e.Value = entry
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[user.ID] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*discordUserCacheEntry).user.ID)
+	}
+}
+
+// invalidate removes a user from the cache
+func (c *discordUserCache) invalidate(uid int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if e, ok := c.entries[uid]; ok {
+		c.order.Remove(e)
+		delete(c.entries, uid)
+	}
+}
+
+// resize changes cache capacity and TTL, dropping all cached users
+func (c *discordUserCache) resize(size int, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.size = size
+	c.ttl = ttl
+	c.order.Init()
+	c.entries = make(map[int64]*list.Element)
+}
+
+// SetDiscordUserCachePolicy configures how many discord users are cached and for how long, size 0 disables the cache
+func (d *mysqlDAL) SetDiscordUserCachePolicy(size int, ttl time.Duration) {
+	d.discordUsers.resize(size, ttl)
+}
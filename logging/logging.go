@@ -74,7 +74,7 @@ func LogRequestHandler(l *logrus.Entry, h http.Handler) http.Handler {
 			userAgent: r.Header.Get("User-Agent"),
 		}
 
-		ri.ipaddr = requestGetRemoteAddress(r)
+		ri.ipaddr = RequestGetRemoteAddress(r)
 
 		// this runs handler h and captures information about
 		// HTTP request
@@ -97,9 +97,9 @@ func ipAddrFromRemoteAddr(s string) string {
 	return s[:idx]
 }
 
-// requestGetRemoteAddress returns ip address of the client making the request,
+// RequestGetRemoteAddress returns ip address of the client making the request,
 // taking into account http proxies
-func requestGetRemoteAddress(r *http.Request) string {
+func RequestGetRemoteAddress(r *http.Request) string {
 	hdr := r.Header
 	hdrRealIP := hdr.Get("X-Real-Ip")
 	hdrForwardedFor := hdr.Get("X-Forwarded-For")
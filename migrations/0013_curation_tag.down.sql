@@ -0,0 +1 @@
+DROP TABLE curation_tag;
\ No newline at end of file
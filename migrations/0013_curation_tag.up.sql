@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS curation_tag
+(
+    id                    BIGINT PRIMARY KEY AUTO_INCREMENT,
+    fk_submission_file_id BIGINT       NOT NULL,
+    name                  VARCHAR(255) NOT NULL,
+    UNIQUE (fk_submission_file_id, name),
+    FOREIGN KEY (fk_submission_file_id) REFERENCES submission_file (id)
+);
+CREATE INDEX idx_curation_tag_name ON curation_tag (name);
+
+INSERT IGNORE INTO curation_tag (fk_submission_file_id, name)
+SELECT curation_meta.fk_submission_file_id, TRIM(tag.name)
+FROM curation_meta,
+     JSON_TABLE(
+             CONCAT('["', REPLACE(REPLACE(REPLACE(curation_meta.tags, '\\', '\\\\'), '"', '\\"'), ';', '","'), '"]'),
+             '$[*]' COLUMNS (name VARCHAR(255) PATH '$')
+         ) AS tag
+WHERE curation_meta.tags IS NOT NULL
+  AND TRIM(tag.name) != '';
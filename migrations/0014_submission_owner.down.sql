@@ -0,0 +1,4 @@
+ALTER TABLE submission
+    DROP FOREIGN KEY submission_fk_owner_id;
+ALTER TABLE submission
+    DROP COLUMN fk_owner_id;
@@ -0,0 +1,10 @@
+ALTER TABLE submission
+    ADD fk_owner_id BIGINT DEFAULT NULL,
+    ADD CONSTRAINT submission_fk_owner_id FOREIGN KEY (fk_owner_id) REFERENCES discord_user (id);
+
+UPDATE submission
+SET fk_owner_id = (SELECT fk_user_id
+                   FROM submission_file
+                   WHERE submission_file.fk_submission_id = submission.id
+                   ORDER BY created_at
+                   LIMIT 1);
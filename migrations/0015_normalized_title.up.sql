@@ -0,0 +1,3 @@
+ALTER TABLE curation_meta
+    ADD normalized_title TEXT GENERATED ALWAYS AS (REGEXP_REPLACE(LOWER(title), '[[:punct:][:space:]]', '')) STORED,
+    ADD INDEX (normalized_title(255));
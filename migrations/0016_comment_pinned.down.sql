@@ -0,0 +1,4 @@
+ALTER TABLE comment
+    DROP FOREIGN KEY comment_fk_pinned_by_id,
+    DROP COLUMN fk_pinned_by_id,
+    DROP COLUMN pinned;
@@ -0,0 +1,4 @@
+ALTER TABLE comment
+    ADD pinned          BOOL   NOT NULL DEFAULT FALSE,
+    ADD fk_pinned_by_id BIGINT          DEFAULT NULL,
+    ADD CONSTRAINT comment_fk_pinned_by_id FOREIGN KEY (fk_pinned_by_id) REFERENCES discord_user (id);
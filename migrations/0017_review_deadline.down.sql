@@ -0,0 +1,3 @@
+DROP INDEX idx_submission_review_deadline ON submission;
+ALTER TABLE submission
+    DROP COLUMN review_deadline;
@@ -0,0 +1,3 @@
+ALTER TABLE submission
+    ADD review_deadline BIGINT DEFAULT NULL;
+CREATE INDEX idx_submission_review_deadline ON submission (review_deadline);
@@ -0,0 +1,14 @@
+ALTER TABLE curation_meta
+    ADD normalized_source TEXT DEFAULT NULL,
+    ADD INDEX (normalized_source(255));
+UPDATE curation_meta
+SET normalized_source = CASE
+    WHEN TRIM(source) REGEXP '^[A-Za-z][A-Za-z0-9+.-]*://[^/?#]+'
+        THEN CONCAT(
+            LOWER(REGEXP_SUBSTR(TRIM(source), '^[A-Za-z][A-Za-z0-9+.-]*://[^/?#]+')),
+            REGEXP_REPLACE(
+                SUBSTRING(TRIM(source), CHAR_LENGTH(REGEXP_SUBSTR(TRIM(source), '^[A-Za-z][A-Za-z0-9+.-]*://[^/?#]+')) + 1),
+                '[?#].*$', ''))
+    ELSE TRIM(source)
+    END
+WHERE source IS NOT NULL;
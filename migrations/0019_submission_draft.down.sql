@@ -0,0 +1,2 @@
+ALTER TABLE submission
+    DROP COLUMN is_draft;
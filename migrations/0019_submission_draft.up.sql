@@ -0,0 +1,3 @@
+-- submissions are published by default, drafts are opt-in and stay hidden from reviewers until published
+ALTER TABLE submission
+    ADD is_draft BOOL NOT NULL DEFAULT FALSE;
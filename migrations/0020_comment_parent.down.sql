@@ -0,0 +1,3 @@
+ALTER TABLE comment
+    DROP FOREIGN KEY comment_fk_parent_comment_id,
+    DROP COLUMN fk_parent_comment_id;
@@ -0,0 +1,3 @@
+ALTER TABLE comment
+    ADD fk_parent_comment_id BIGINT DEFAULT NULL,
+    ADD CONSTRAINT comment_fk_parent_comment_id FOREIGN KEY (fk_parent_comment_id) REFERENCES comment (id);
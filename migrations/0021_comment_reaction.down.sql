@@ -0,0 +1 @@
+DROP TABLE comment_reaction;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS comment_reaction
+(
+    id            BIGINT PRIMARY KEY AUTO_INCREMENT,
+    fk_comment_id BIGINT      NOT NULL,
+    fk_user_id    BIGINT      NOT NULL,
+    reaction      VARCHAR(32) NOT NULL,
+    created_at    BIGINT      NOT NULL,
+    UNIQUE (fk_comment_id, fk_user_id, reaction),
+    FOREIGN KEY (fk_comment_id) REFERENCES comment (id),
+    FOREIGN KEY (fk_user_id) REFERENCES discord_user (id)
+);
@@ -0,0 +1,3 @@
+DROP INDEX idx_submission_status ON submission;
+ALTER TABLE submission
+    DROP COLUMN status;
@@ -0,0 +1,26 @@
+ALTER TABLE submission
+    ADD status VARCHAR(31) NOT NULL DEFAULT 'new';
+CREATE INDEX idx_submission_status ON submission (status);
+
+-- derive the status of existing submissions from their latest state-changing human action, the validator bot is not a reviewer
+UPDATE submission
+    JOIN (SELECT comment.fk_submission_id,
+                 action.name,
+                 ROW_NUMBER() OVER (PARTITION BY comment.fk_submission_id ORDER BY comment.created_at DESC, comment.id DESC) AS rn
+          FROM comment
+                   JOIN action ON action.id = comment.fk_action_id
+          WHERE comment.deleted_at IS NULL
+            AND comment.fk_user_id != 810112564787675166
+            AND action.name IN ('upload-file', 'assign-testing', 'assign-verification', 'approve', 'verify',
+                                'request-changes', 'reject', 'mark-added')) AS latest_action
+    ON latest_action.fk_submission_id = submission.id AND latest_action.rn = 1
+SET submission.status = CASE latest_action.name
+                            WHEN 'mark-added' THEN 'accepted'
+                            WHEN 'reject' THEN 'rejected'
+                            WHEN 'request-changes' THEN 'needs-changes'
+                            WHEN 'approve' THEN 'approved'
+                            WHEN 'verify' THEN 'approved'
+                            WHEN 'assign-testing' THEN 'in-review'
+                            WHEN 'assign-verification' THEN 'in-review'
+                            ELSE 'new'
+    END;
@@ -0,0 +1 @@
+DROP TABLE submission_meta;
\ No newline at end of file
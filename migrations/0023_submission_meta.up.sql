@@ -0,0 +1,10 @@
+-- free-form key/value data attached to submissions by individual teams, intentionally without a fixed schema
+CREATE TABLE IF NOT EXISTS submission_meta
+(
+    id               BIGINT PRIMARY KEY AUTO_INCREMENT,
+    fk_submission_id BIGINT       NOT NULL,
+    meta_key         VARCHAR(255) NOT NULL,
+    meta_value       TEXT         NOT NULL,
+    UNIQUE (fk_submission_id, meta_key),
+    FOREIGN KEY (fk_submission_id) REFERENCES submission (id)
+);
@@ -0,0 +1,2 @@
+DELETE FROM notification_settings WHERE fk_action_id = 15;
+DELETE FROM action WHERE id = 15;
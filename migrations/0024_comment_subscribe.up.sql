@@ -0,0 +1,7 @@
+INSERT IGNORE INTO action (id, name)
+VALUES (15, 'comment-subscribe');
+
+-- subscribing to submissions on comment is opt-out, enable it for existing users
+INSERT INTO notification_settings (fk_user_id, fk_action_id)
+SELECT discord_user.id, 15
+FROM discord_user;
@@ -0,0 +1,3 @@
+-- creation time of sessions which predate this column is unknown, they count as older than any cutoff
+ALTER TABLE session
+    ADD created_at BIGINT DEFAULT NULL;
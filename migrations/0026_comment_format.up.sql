@@ -0,0 +1,2 @@
+ALTER TABLE comment
+    ADD format VARCHAR(16) NOT NULL DEFAULT 'plain';
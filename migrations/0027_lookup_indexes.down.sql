@@ -0,0 +1,5 @@
+DROP INDEX idx_session_expires_at ON session;
+DROP INDEX idx_session_uid ON session;
+DROP INDEX idx_session_secret ON session;
+DROP INDEX idx_submission_file_submission_created_at ON submission_file;
+DROP INDEX idx_comment_submission_user_created_at ON comment;
@@ -0,0 +1,6 @@
+-- foreign key columns are already indexed by InnoDB, these cover the lookups which also filter or sort on other columns
+CREATE INDEX idx_comment_submission_user_created_at ON comment (fk_submission_id, fk_user_id, created_at);
+CREATE INDEX idx_submission_file_submission_created_at ON submission_file (fk_submission_id, created_at);
+CREATE INDEX idx_session_secret ON session (secret);
+CREATE INDEX idx_session_uid ON session (uid);
+CREATE INDEX idx_session_expires_at ON session (expires_at);
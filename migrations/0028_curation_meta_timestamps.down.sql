@@ -0,0 +1,3 @@
+ALTER TABLE curation_meta
+    DROP COLUMN created_at,
+    DROP COLUMN updated_at;
@@ -0,0 +1,10 @@
+ALTER TABLE curation_meta
+    ADD created_at BIGINT DEFAULT NULL,
+    ADD updated_at BIGINT DEFAULT NULL;
+UPDATE curation_meta
+    JOIN submission_file ON submission_file.id = curation_meta.fk_submission_file_id
+SET curation_meta.created_at = submission_file.created_at,
+    curation_meta.updated_at = submission_file.created_at;
+ALTER TABLE curation_meta
+    MODIFY created_at BIGINT NOT NULL,
+    MODIFY updated_at BIGINT NOT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX idx_submission_priority ON submission;
+ALTER TABLE submission
+    DROP COLUMN priority;
@@ -0,0 +1,3 @@
+ALTER TABLE submission
+    ADD priority BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX idx_submission_priority ON submission (priority);
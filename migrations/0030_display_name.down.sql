@@ -0,0 +1,2 @@
+ALTER TABLE discord_user
+    DROP COLUMN display_name;
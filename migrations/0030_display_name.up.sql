@@ -0,0 +1,2 @@
+ALTER TABLE discord_user
+    ADD display_name VARCHAR(127) DEFAULT NULL;
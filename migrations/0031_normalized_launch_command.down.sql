@@ -0,0 +1,2 @@
+ALTER TABLE curation_meta
+    DROP COLUMN normalized_launch_command;
@@ -0,0 +1,7 @@
+ALTER TABLE curation_meta
+    ADD normalized_launch_command TEXT DEFAULT NULL,
+    ADD INDEX (normalized_launch_command(255));
+-- same as strings.TrimSpace in normalizeCurationMetaFields, plain TRIM only strips spaces
+UPDATE curation_meta
+SET normalized_launch_command = NULLIF(REGEXP_REPLACE(launch_command, '^[[:space:]]+|[[:space:]]+$', ''), '')
+WHERE launch_command IS NOT NULL;
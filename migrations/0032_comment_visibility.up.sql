@@ -0,0 +1,2 @@
+ALTER TABLE comment
+    ADD visibility VARCHAR(16) NOT NULL DEFAULT 'public';
@@ -0,0 +1 @@
+DROP TABLE curation_language;
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS curation_language
+(
+    id                    BIGINT PRIMARY KEY AUTO_INCREMENT,
+    fk_submission_file_id BIGINT       NOT NULL,
+    code                  VARCHAR(255) NOT NULL,
+    is_known              BOOL         NOT NULL, -- two-letter ISO 639-1 code, unknown codes are kept as they were written
+    UNIQUE (fk_submission_file_id, code),
+    FOREIGN KEY (fk_submission_file_id) REFERENCES submission_file (id)
+);
+CREATE INDEX idx_curation_language_code ON curation_language (code);
+
+INSERT IGNORE INTO curation_language (fk_submission_file_id, code, is_known)
+SELECT curation_meta.fk_submission_file_id,
+       LOWER(TRIM(language.code)),
+       LOWER(TRIM(language.code)) IN ('aa', 'ab', 'ae', 'af', 'ak', 'am', 'an', 'ar', 'as', 'av', 'ay', 'az', 'ba', 'be', 'bg', 'bh', 'bi', 'bm', 'bn', 'bo',
+                   'br', 'bs', 'ca', 'ce', 'ch', 'co', 'cr', 'cs', 'cu', 'cv', 'cy', 'da', 'de', 'dv', 'dz', 'ee', 'el', 'en', 'eo', 'es',
+                   'et', 'eu', 'fa', 'ff', 'fi', 'fj', 'fo', 'fr', 'fy', 'ga', 'gd', 'gl', 'gn', 'gu', 'gv', 'ha', 'he', 'hi', 'ho', 'hr',
+                   'ht', 'hu', 'hy', 'hz', 'ia', 'id', 'ie', 'ig', 'ii', 'ik', 'io', 'is', 'it', 'iu', 'ja', 'jv', 'ka', 'kg', 'ki', 'kj',
+                   'kk', 'kl', 'km', 'kn', 'ko', 'kr', 'ks', 'ku', 'kv', 'kw', 'ky', 'la', 'lb', 'lg', 'li', 'ln', 'lo', 'lt', 'lu', 'lv',
+                   'mg', 'mh', 'mi', 'mk', 'ml', 'mn', 'mr', 'ms', 'mt', 'my', 'na', 'nb', 'nd', 'ne', 'ng', 'nl', 'nn', 'no', 'nr', 'nv',
+                   'ny', 'oc', 'oj', 'om', 'or', 'os', 'pa', 'pi', 'pl', 'ps', 'pt', 'qu', 'rm', 'rn', 'ro', 'ru', 'rw', 'sa', 'sc', 'sd',
+                   'se', 'sg', 'si', 'sk', 'sl', 'sm', 'sn', 'so', 'sq', 'sr', 'ss', 'st', 'su', 'sv', 'sw', 'ta', 'te', 'tg', 'th', 'ti',
+                   'tk', 'tl', 'tn', 'to', 'tr', 'ts', 'tt', 'tw', 'ty', 'ug', 'uk', 'ur', 'uz', 've', 'vi', 'vo', 'wa', 'wo', 'xh', 'yi',
+                   'yo', 'za', 'zh', 'zu')
+FROM curation_meta,
+     JSON_TABLE(
+             CONCAT('["', REPLACE(REPLACE(REPLACE(curation_meta.languages, '\\', '\\\\'), '"', '\\"'), ';', '","'), '"]'),
+             '$[*]' COLUMNS (code VARCHAR(255) PATH '$')
+         ) AS language
+WHERE curation_meta.languages IS NOT NULL
+  AND TRIM(language.code) != '';
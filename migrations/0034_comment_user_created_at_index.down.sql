@@ -0,0 +1 @@
+DROP INDEX idx_comment_user_created_at ON comment;
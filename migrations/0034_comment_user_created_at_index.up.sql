@@ -0,0 +1,2 @@
+-- covers comment rate checks, which count comments of a single user in a trailing window
+CREATE INDEX idx_comment_user_created_at ON comment (fk_user_id, created_at);
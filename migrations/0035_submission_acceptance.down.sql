@@ -0,0 +1,4 @@
+DROP INDEX idx_submission_accepted_at ON submission;
+ALTER TABLE submission
+    DROP COLUMN accepted_game_id,
+    DROP COLUMN accepted_at;
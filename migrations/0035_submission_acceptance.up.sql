@@ -0,0 +1,4 @@
+ALTER TABLE submission
+    ADD accepted_at      BIGINT      NULL DEFAULT NULL,
+    ADD accepted_game_id VARCHAR(36) NULL DEFAULT NULL;
+CREATE INDEX idx_submission_accepted_at ON submission (accepted_at);
@@ -0,0 +1,3 @@
+DELETE
+FROM discord_user
+WHERE id = 1;
@@ -0,0 +1,2 @@
+INSERT INTO discord_user (id, username, avatar, discriminator, public_flags, flags, locale, mfa_enabled)
+VALUES (1, 'Anonymized user', '', '0000', 0, 0, '', 0);
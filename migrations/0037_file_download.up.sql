@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS file_download
+(
+    id                    BIGINT PRIMARY KEY AUTO_INCREMENT,
+    fk_submission_file_id BIGINT      NOT NULL,
+    fk_user_id            BIGINT      NOT NULL,
+    ip                    VARCHAR(45) NOT NULL,
+    downloaded_at         BIGINT      NOT NULL,
+    FOREIGN KEY (fk_submission_file_id) REFERENCES submission_file (id),
+    FOREIGN KEY (fk_user_id) REFERENCES discord_user (id)
+);
+CREATE INDEX idx_file_download_file_downloaded_at ON file_download (fk_submission_file_id, downloaded_at);
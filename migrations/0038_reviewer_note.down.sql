@@ -0,0 +1 @@
+DROP TABLE reviewer_note;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS reviewer_note
+(
+    id               BIGINT PRIMARY KEY AUTO_INCREMENT,
+    fk_submission_id BIGINT     NOT NULL,
+    fk_user_id       BIGINT     NOT NULL,
+    body             MEDIUMTEXT NOT NULL,
+    created_at       BIGINT     NOT NULL,
+    updated_at       BIGINT     NOT NULL,
+    UNIQUE (fk_submission_id, fk_user_id),
+    FOREIGN KEY (fk_submission_id) REFERENCES submission (id),
+    FOREIGN KEY (fk_user_id) REFERENCES discord_user (id)
+);
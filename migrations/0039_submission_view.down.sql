@@ -0,0 +1,3 @@
+DROP TABLE submission_view;
+ALTER TABLE submission
+    DROP COLUMN view_count;
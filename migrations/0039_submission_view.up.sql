@@ -0,0 +1,13 @@
+ALTER TABLE submission
+    ADD view_count BIGINT NOT NULL DEFAULT 0;
+
+-- one row per viewer and day, view_count counts the rows
+CREATE TABLE IF NOT EXISTS submission_view
+(
+    fk_submission_id BIGINT NOT NULL,
+    fk_user_id       BIGINT NOT NULL,
+    view_date        DATE   NOT NULL,
+    PRIMARY KEY (fk_submission_id, view_date, fk_user_id),
+    FOREIGN KEY (fk_submission_id) REFERENCES submission (id),
+    FOREIGN KEY (fk_user_id) REFERENCES discord_user (id)
+);
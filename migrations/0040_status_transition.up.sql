@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS status_transition
+(
+    id               BIGINT PRIMARY KEY AUTO_INCREMENT,
+    fk_submission_id BIGINT      NOT NULL,
+    from_status      VARCHAR(31) NOT NULL,
+    to_status        VARCHAR(31) NOT NULL,
+    fk_actor_id      BIGINT      NOT NULL,
+    created_at       BIGINT      NOT NULL,
+    FOREIGN KEY (fk_submission_id) REFERENCES submission (id),
+    FOREIGN KEY (fk_actor_id) REFERENCES discord_user (id)
+);
+CREATE INDEX idx_status_transition_submission_created_at ON status_transition (fk_submission_id, created_at);
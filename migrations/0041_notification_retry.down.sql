@@ -0,0 +1,5 @@
+DROP INDEX idx_submission_notification_next_attempt_at ON submission_notification;
+ALTER TABLE submission_notification
+    DROP COLUMN failed_at,
+    DROP COLUMN next_attempt_at,
+    DROP COLUMN attempts;
@@ -0,0 +1,5 @@
+ALTER TABLE submission_notification
+    ADD attempts        INT    NOT NULL DEFAULT 0,
+    ADD next_attempt_at BIGINT NULL DEFAULT NULL,
+    ADD failed_at       BIGINT NULL DEFAULT NULL;
+CREATE INDEX idx_submission_notification_next_attempt_at ON submission_notification (next_attempt_at);
@@ -0,0 +1 @@
+DROP TABLE tag_category;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS tag_category
+(
+    id   BIGINT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(255) NOT NULL,
+    UNIQUE (name)
+);
+
+INSERT INTO tag_category (name)
+VALUES ('default'),
+       ('genre'),
+       ('theme'),
+       ('setting'),
+       ('presentation'),
+       ('gameplay'),
+       ('mature'),
+       ('content warning');
@@ -0,0 +1,8 @@
+DROP TABLE file_download_archive;
+DROP TABLE status_transition_archive;
+DROP TABLE submission_meta_archive;
+DROP TABLE comment_archive;
+DROP TABLE curation_image_archive;
+DROP TABLE curation_meta_archive;
+DROP TABLE submission_file_archive;
+DROP TABLE submission_archive;
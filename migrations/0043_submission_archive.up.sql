@@ -0,0 +1,16 @@
+-- archived submissions keep their rows verbatim, so these tables have to follow every column change of the live tables.
+-- LIKE copies indexes but not foreign keys, archived rows may outlive what they refer to.
+CREATE TABLE IF NOT EXISTS submission_archive LIKE submission;
+CREATE TABLE IF NOT EXISTS submission_file_archive LIKE submission_file;
+CREATE TABLE IF NOT EXISTS curation_meta_archive LIKE curation_meta;
+CREATE TABLE IF NOT EXISTS curation_image_archive LIKE curation_image;
+CREATE TABLE IF NOT EXISTS comment_archive LIKE comment;
+CREATE TABLE IF NOT EXISTS submission_meta_archive LIKE submission_meta;
+CREATE TABLE IF NOT EXISTS status_transition_archive LIKE status_transition;
+CREATE TABLE IF NOT EXISTS file_download_archive LIKE file_download;
+
+-- an archived file no longer blocks uploading the same file again, and that upload may end up archived too
+ALTER TABLE submission_file_archive
+    DROP INDEX md5sum,
+    DROP INDEX sha256sum;
+CREATE INDEX idx_submission_file_archive_sha256sum ON submission_file_archive (sha256sum);
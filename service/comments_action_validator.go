@@ -187,5 +187,12 @@ func isActionValidForSubmission(uid int64, formAction string, submission *types.
 		}
 	}
 
+	// the status the action leads to has to be reachable from the current one, the recompute records whatever follows from the comments
+	if status, ok := constants.SubmissionStatusForAction(formAction); ok && submission.Status != "" && submission.Status != status {
+		if !constants.IsStatusTransitionAllowed(submission.Status, status) {
+			return perr(fmt.Sprintf("submission %d is %s so it cannot become %s", sid, submission.Status, status), http.StatusBadRequest)
+		}
+	}
+
 	return nil
 }
@@ -291,6 +291,24 @@ func Test_isActionValidForSubmission(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "user cannot request changes on an accepted submission",
+			args: args{
+				uid:        commenterID,
+				formAction: constants.ActionRequestChanges,
+				submission: &types.ExtendedSubmission{Status: constants.SubmissionStatusAccepted},
+			},
+			wantErr: true,
+		},
+		{
+			name: "user can request changes on an approved submission",
+			args: args{
+				uid:        commenterID,
+				formAction: constants.ActionRequestChanges,
+				submission: &types.ExtendedSubmission{Status: constants.SubmissionStatusApproved},
+			},
+			wantErr: false,
+		},
 		{
 			name: "user cannot upload a submission that's already rejected",
 			args: args{
@@ -21,11 +21,17 @@ func (s *SiteService) RunNotificationConsumer(logger *logrus.Entry, ctx context.
 
 	const errorSleepTime = time.Second * 60
 
+	// failed notifications become due again without anything announcing them
+	retryTicker := time.NewTicker(errorSleepTime)
+	defer retryTicker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			l.Info("context cancelled, stopping notification consumer")
 			return
+		case <-retryTicker.C:
+			s.announceNotification()
 		case <-s.notificationQueueNotEmpty:
 			select {
 			case <-ctx.Done():
@@ -68,6 +74,16 @@ func (s *SiteService) RunNotificationConsumer(logger *logrus.Entry, ctx context.
 
 				if err := s.notificationBot.SendNotification(notification.Message, notification.Type); err != nil {
 					l.Error(err)
+					// back off exponentially, so a notification the bot keeps failing on does not block the queue
+					retryAfter := errorSleepTime << notification.Attempts
+					if err := s.dal.MarkNotificationFailed(dbs, notification.ID, retryAfter); err != nil {
+						l.Error(err)
+					} else if err := dbs.Commit(); err != nil {
+						l.Error(err)
+					} else {
+						// the notification is out of the way until it is due again, the retry ticker announces it then
+						return
+					}
 					l.Debugf("sleeping for %f seconds", errorSleepTime.Seconds())
 					time.Sleep(errorSleepTime)
 					return
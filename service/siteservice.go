@@ -140,16 +140,17 @@ type SiteService struct {
 	archiveIndexerServerURL   string
 	flashfreezeIngestDir      string
 	fixesDir                  string
+	maxFilesPerSubmission     int // 0 means unlimited
 }
 
 func New(l *logrus.Entry, db *sql.DB, authBotSession, notificationBotSession *discordgo.Session,
 	flashpointServerID, notificationChannelID, curationFeedChannelID, validatorServerURL string,
-	sessionExpirationSeconds int64, submissionsDir, submissionImagesDir, flashfreezeDir string, isDev bool, rsu *resumableuploadservice.ResumableUploadService, archiveIndexerServerURL, flashfreezeIngestDir, fixesDir string) *SiteService {
+	sessionExpirationSeconds int64, submissionsDir, submissionImagesDir, flashfreezeDir string, isDev bool, rsu *resumableuploadservice.ResumableUploadService, archiveIndexerServerURL, flashfreezeIngestDir, fixesDir string, dbSlowQueryThreshold time.Duration, maxFilesPerSubmission int) *SiteService {
 
 	return &SiteService{
 		authBot:                   authbot.NewBot(authBotSession, flashpointServerID, l.WithField("botName", "authBot"), isDev),
 		notificationBot:           notificationbot.NewBot(notificationBotSession, flashpointServerID, notificationChannelID, curationFeedChannelID, l.WithField("botName", "notificationBot"), isDev),
-		dal:                       database.NewMysqlDAL(db),
+		dal:                       database.NewMysqlDAL(l.WithField("serviceName", "database"), db, dbSlowQueryThreshold),
 		validator:                 NewValidator(validatorServerURL),
 		clock:                     &RealClock{},
 		randomStringProvider:      utils.NewRealRandomStringProvider(),
@@ -165,9 +166,20 @@ func New(l *logrus.Entry, db *sql.DB, authBotSession, notificationBotSession *di
 		archiveIndexerServerURL:   archiveIndexerServerURL,
 		flashfreezeIngestDir:      flashfreezeIngestDir,
 		fixesDir:                  fixesDir,
+		maxFilesPerSubmission:     maxFilesPerSubmission,
 	}
 }
 
+// Close releases resources held by the service
+func (s *SiteService) Close() error {
+	return s.dal.Close()
+}
+
+// RunDBStatsPrinter periodically logs database connection pool statistics
+func (s *SiteService) RunDBStatsPrinter(l *logrus.Entry, ctx context.Context, wg *sync.WaitGroup) {
+	s.dal.LogStats(l.WithField("serviceName", "dbStatsPrinter"), ctx, wg)
+}
+
 // GetBasePageData loads base user data, does not return error if user is not logged in
 func (s *SiteService) GetBasePageData(ctx context.Context) (*types.BasePageData, error) {
 	dbs, err := s.dal.NewSession(ctx)
@@ -193,8 +205,13 @@ func (s *SiteService) GetBasePageData(ctx context.Context) (*types.BasePageData,
 		return nil, dberr(err)
 	}
 
+	username := discordUser.Username
+	if discordUser.DisplayName != nil {
+		username = *discordUser.DisplayName
+	}
+
 	bpd := &types.BasePageData{
-		Username:      discordUser.Username,
+		Username:      username,
 		UserID:        discordUser.ID,
 		AvatarURL:     utils.FormatAvatarURL(discordUser.ID, discordUser.Avatar),
 		UserRoles:     userRoles,
@@ -217,32 +234,29 @@ func (s *SiteService) GetViewSubmissionPageData(ctx context.Context, uid, sid in
 		return nil, err
 	}
 
-	filter := &types.SubmissionsFilter{
-		SubmissionIDs: []int64{sid},
+	visibility := constants.CommentVisibilityPublic
+	if constants.IsStaff(bpd.UserRoles) {
+		visibility = ""
 	}
 
-	submissions, _, err := s.dal.SearchSubmissions(dbs, filter)
+	detail, err := s.dal.GetSubmissionDetail(dbs, sid, visibility)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, perr("submission not found", http.StatusNotFound)
+		}
 		utils.LogCtx(ctx).Error(err)
 		return nil, dberr(err)
 	}
 
-	if len(submissions) == 0 {
-		return nil, perr("submission not found", http.StatusNotFound)
-	}
-
-	submission := submissions[0]
-
-	meta, err := s.dal.GetCurationMetaBySubmissionFileID(dbs, submission.FileID)
-	if err != nil && err != sql.ErrNoRows {
+	if err := s.dal.PopulateCommentReactions(dbs, detail.Comments, uid); err != nil {
 		utils.LogCtx(ctx).Error(err)
 		return nil, dberr(err)
 	}
 
-	comments, err := s.dal.GetExtendedCommentsBySubmissionID(dbs, sid)
-	if err != nil {
-		utils.LogCtx(ctx).Error(err)
-		return nil, dberr(err)
+	// owners looking at their own submission do not count as attention
+	if detail.Submission.OwnerID != uid {
+		l := utils.LogCtx(ctx).WithField("submissionID", sid)
+		go s.recordSubmissionView(l, sid, uid)
 	}
 
 	isUserSubscribed, err := s.dal.IsUserSubscribedToSubmission(dbs, uid, sid)
@@ -251,15 +265,9 @@ func (s *SiteService) GetViewSubmissionPageData(ctx context.Context, uid, sid in
 		return nil, dberr(err)
 	}
 
-	curationImages, err := s.dal.GetCurationImagesBySubmissionFileID(dbs, submission.FileID)
-	if err != nil {
-		utils.LogCtx(ctx).Error(err)
-		return nil, dberr(err)
-	}
-
-	ciids := make([]int64, 0, len(curationImages))
+	ciids := make([]int64, 0, len(detail.CurationImages))
 
-	for _, curationImage := range curationImages {
+	for _, curationImage := range detail.CurationImages {
 		ciids = append(ciids, curationImage.ID)
 	}
 
@@ -294,10 +302,10 @@ func (s *SiteService) GetViewSubmissionPageData(ctx context.Context, uid, sid in
 	pageData := &types.ViewSubmissionPageData{
 		SubmissionsPageData: types.SubmissionsPageData{
 			BasePageData: *bpd,
-			Submissions:  submissions,
+			Submissions:  []*types.ExtendedSubmission{detail.Submission},
 		},
-		CurationMeta:         meta,
-		Comments:             comments,
+		CurationMeta:         detail.CurationMeta,
+		Comments:             detail.Comments,
 		IsUserSubscribed:     isUserSubscribed,
 		CurationImageIDs:     ciids,
 		NextSubmissionID:     nextSID,
@@ -396,6 +404,57 @@ func (s *SiteService) GetSubmissionFiles(ctx context.Context, sfids []int64) ([]
 	return sfs, nil
 }
 
+func (s *SiteService) recordSubmissionView(l *logrus.Entry, sid, uid int64) {
+	ctx := context.WithValue(context.Background(), utils.CtxKeys.Log, l)
+
+	dbs, err := s.dal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return
+	}
+	defer dbs.Rollback()
+
+	if err := s.dal.IncrementSubmissionViews(dbs, sid, uid); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return
+	}
+
+	if err := dbs.Commit(); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return
+	}
+}
+
+// RecordFileDownloads records downloads of submission files by the current user in the background, so the download itself does not wait for it
+func (s *SiteService) RecordFileDownloads(ctx context.Context, sfids []int64, ip string) {
+	uid := utils.UserID(ctx)
+	l := utils.LogCtx(ctx).WithField("submissionFileIDs", sfids)
+	go s.recordFileDownloads(l, uid, sfids, ip)
+}
+
+func (s *SiteService) recordFileDownloads(l *logrus.Entry, uid int64, sfids []int64, ip string) {
+	ctx := context.WithValue(context.Background(), utils.CtxKeys.Log, l)
+
+	dbs, err := s.dal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return
+	}
+	defer dbs.Rollback()
+
+	for _, sfid := range sfids {
+		if err := s.dal.RecordFileDownload(dbs, sfid, uid, ip); err != nil {
+			utils.LogCtx(ctx).Error(err)
+			return
+		}
+	}
+
+	if err := dbs.Commit(); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return
+	}
+}
+
 func (s *SiteService) GetUIDFromSession(ctx context.Context, key string) (int64, bool, error) {
 	dbs, err := s.dal.NewSession(ctx)
 	if err != nil {
@@ -535,6 +594,85 @@ func (s *SiteService) SoftDeleteComment(ctx context.Context, cid int64, deleteRe
 	return nil
 }
 
+func (s *SiteService) SetCommentPinned(ctx context.Context, sid, cid int64, pinned bool) error {
+	uid := utils.UserID(ctx)
+
+	dbs, err := s.dal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	defer dbs.Rollback()
+
+	c, err := s.dal.GetCommentByID(dbs, cid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		if err == sql.ErrNoRows {
+			return perr("comment not found", http.StatusNotFound)
+		}
+		return dberr(err)
+	}
+	if c.SubmissionID != sid {
+		return perr("comment not found", http.StatusNotFound)
+	}
+
+	if pinned {
+		err = s.dal.PinComment(dbs, cid, uid)
+	} else {
+		err = s.dal.UnpinComment(dbs, cid)
+	}
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	return nil
+}
+
+func (s *SiteService) SetCommentReaction(ctx context.Context, cid int64, reaction string, react bool) error {
+	uid := utils.UserID(ctx)
+
+	dbs, err := s.dal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	defer dbs.Rollback()
+
+	if _, err := s.dal.GetCommentByID(dbs, cid); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		if err == sql.ErrNoRows {
+			return perr("comment not found", http.StatusNotFound)
+		}
+		return dberr(err)
+	}
+
+	if react {
+		err = s.dal.AddCommentReaction(dbs, cid, uid, reaction)
+	} else {
+		err = s.dal.RemoveCommentReaction(dbs, cid, uid, reaction)
+	}
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		if err.Error() == constants.ErrorInvalidCommentReaction {
+			return perr(err.Error(), http.StatusBadRequest)
+		}
+		return dberr(err)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	return nil
+}
+
 func (s *SiteService) OverrideBot(ctx context.Context, sid int64) error {
 	uid := utils.UserID(ctx)
 
@@ -573,6 +711,31 @@ func (s *SiteService) OverrideBot(ctx context.Context, sid int64) error {
 	return nil
 }
 
+// PublishSubmission makes a draft submission visible to reviewers
+func (s *SiteService) PublishSubmission(ctx context.Context, sid int64) error {
+	dbs, err := s.dal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	defer dbs.Rollback()
+
+	if err := s.dal.PublishSubmission(dbs, sid); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		if err == sql.ErrNoRows {
+			return perr("submission not found", http.StatusNotFound)
+		}
+		return dberr(err)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	return nil
+}
+
 func (s *SiteService) SaveUser(ctx context.Context, discordUser *types.DiscordUser) (*authToken, error) {
 	getServerRoles := func() (interface{}, error) {
 		return s.authBot.GetFlashpointRoles()
@@ -1255,7 +1418,7 @@ func (s *SiteService) processReceivedResumableSubmission(ctx context.Context, ui
 	}
 
 	ru := newResumableUpload(uid, resumableParams.ResumableIdentifier, resumableParams.ResumableTotalChunks, s.resumableUploadService)
-	destinationFilename, ifp, submissionID, err := s.processReceivedSubmission(ctx, dbs, ru, resumableParams.ResumableFilename, resumableParams.ResumableTotalSize, sid, submissionLevel)
+	destinationFilename, ifp, submissionID, err := s.processReceivedSubmission(ctx, dbs, ru, resumableParams.ResumableFilename, resumableParams.ResumableTotalSize, sid, submissionLevel, resumableParams.Draft)
 
 	for _, imageFilePath := range ifp {
 		imageFilePaths = append(imageFilePaths, imageFilePath)
@@ -10,7 +10,7 @@ import (
 	"time"
 )
 
-func (s *SiteService) ReceiveComments(ctx context.Context, uid int64, sids []int64, formAction, formMessage, formIgnoreDupeActions string) error {
+func (s *SiteService) ReceiveComments(ctx context.Context, uid int64, sids []int64, formAction, formMessage, formFormat, formVisibility, formIgnoreDupeActions string) error {
 	dbs, err := s.dal.NewSession(ctx)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
@@ -50,6 +50,46 @@ func (s *SiteService) ReceiveComments(ctx context.Context, uid int64, sids []int
 		return perr(fmt.Sprintf("cannot post comment action '%s' without a message", formAction), http.StatusBadRequest)
 	}
 
+	format := constants.CommentFormatPlain
+	if formFormat != "" {
+		isFormatValid := false
+		for _, f := range constants.GetAllowedCommentFormats() {
+			if formFormat == f {
+				isFormatValid = true
+				break
+			}
+		}
+		if !isFormatValid {
+			return perr("invalid comment format", http.StatusBadRequest)
+		}
+		format = formFormat
+	}
+
+	visibility := constants.CommentVisibilityPublic
+	if formVisibility != "" {
+		isVisibilityValid := false
+		for _, v := range constants.GetAllowedCommentVisibilities() {
+			if formVisibility == v {
+				isVisibilityValid = true
+				break
+			}
+		}
+		if !isVisibilityValid {
+			return perr("invalid comment visibility", http.StatusBadRequest)
+		}
+		visibility = formVisibility
+	}
+	if visibility == constants.CommentVisibilityInternal {
+		userRoles, err := s.dal.GetDiscordUserRoles(dbs, uid)
+		if err != nil {
+			utils.LogCtx(ctx).Error(err)
+			return dberr(err)
+		}
+		if !constants.IsStaff(userRoles) {
+			return perr("only staff can post internal comments", http.StatusForbidden)
+		}
+	}
+
 	ignoreDupeActions := false
 	if formIgnoreDupeActions == "true" {
 		ignoreDupeActions = true
@@ -82,6 +122,19 @@ func (s *SiteService) ReceiveComments(ctx context.Context, uid int64, sids []int
 		}
 	}
 
+	notificationActions, err := s.dal.GetNotificationSettingsByUserID(dbs, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	autoSubscribe := false
+	for _, a := range notificationActions {
+		if a == constants.ActionCommentSubscribe {
+			autoSubscribe = true
+			break
+		}
+	}
+
 	commentCounter := 0
 
 	// TODO optimize batch operation even more
@@ -101,6 +154,8 @@ func (s *SiteService) ReceiveComments(ctx context.Context, uid int64, sids []int
 			AuthorID:     uid,
 			SubmissionID: sid,
 			Message:      message,
+			Format:       format,
+			Visibility:   visibility,
 			Action:       formAction,
 			CreatedAt:    s.clock.Now(),
 		}
@@ -113,15 +168,16 @@ func (s *SiteService) ReceiveComments(ctx context.Context, uid int64, sids []int
 			c.Message = nil
 		}
 
-		// subscribe the commenter
-		if formAction == constants.ActionAssignTesting ||
+		// subscribe the commenter, unless they opted out
+		if autoSubscribe && (formAction == constants.ActionComment ||
+			formAction == constants.ActionAssignTesting ||
 			formAction == constants.ActionUnassignTesting ||
 			formAction == constants.ActionAssignVerification ||
 			formAction == constants.ActionUnassignVerification ||
 			formAction == constants.ActionApprove ||
 			formAction == constants.ActionRequestChanges ||
 			formAction == constants.ActionVerify ||
-			formAction == constants.ActionReject {
+			formAction == constants.ActionReject) {
 
 			subscribed, err := s.dal.IsUserSubscribedToSubmission(dbs, uid, sid)
 			if err != nil {
@@ -175,7 +231,7 @@ func (s *SiteService) ReceiveComments(ctx context.Context, uid int64, sids []int
 			return dberr(err)
 		}
 
-		if err := s.dal.UpdateSubmissionCacheTable(dbs, sid); err != nil {
+		if err := s.dal.RecomputeSubmissionDerivedFields(dbs, sid); err != nil {
 			utils.LogCtx(ctx).Error(err)
 			return dberr(err)
 		}
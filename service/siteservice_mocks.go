@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"database/sql"
 	"github.com/Dri0m/flashpoint-submission-system/database"
 	"github.com/Dri0m/flashpoint-submission-system/types"
 	"github.com/stretchr/testify/mock"
@@ -27,9 +26,9 @@ func (m *mockDBSession) Rollback() error {
 	return args.Error(0)
 }
 
-func (m *mockDBSession) Tx() *sql.Tx {
+func (m *mockDBSession) Tx() *database.Tx {
 	args := m.Called()
-	return args.Get(0).(*sql.Tx)
+	return args.Get(0).(*database.Tx)
 }
 
 func (m *mockDBSession) Ctx() context.Context {
@@ -88,8 +87,8 @@ func (m *mockDAL) GetDiscordUserRoles(_ database.DBSession, uid int64) ([]string
 	return args.Get(0).([]string), args.Error(1)
 }
 
-func (m *mockDAL) StoreSubmission(_ database.DBSession, submissionLevel string) (int64, error) {
-	args := m.Called(submissionLevel)
+func (m *mockDAL) StoreSubmission(_ database.DBSession, submissionLevel string, isDraft bool) (int64, error) {
+	args := m.Called(submissionLevel, isDraft)
 	return args.Get(0).(int64), args.Error(1)
 }
 
@@ -128,8 +127,8 @@ func (m *mockDAL) StoreComment(_ database.DBSession, c *types.Comment) error {
 	return args.Error(0)
 }
 
-func (m *mockDAL) GetExtendedCommentsBySubmissionID(_ database.DBSession, sid int64) ([]*types.ExtendedComment, error) {
-	args := m.Called(sid)
+func (m *mockDAL) GetExtendedCommentsBySubmissionID(_ database.DBSession, sid int64, visibility string) ([]*types.ExtendedComment, error) {
+	args := m.Called(sid, visibility)
 	return args.Get(0).([]*types.ExtendedComment), args.Error(1)
 }
 
@@ -23,7 +23,7 @@ import (
 	"time"
 )
 
-func (s *SiteService) processReceivedSubmission(ctx context.Context, dbs database.DBSession, fileReadCloserProvider ReadCloserProvider, filename string, filesize int64, sid *int64, submissionLevel string) (*string, []string, int64, error) {
+func (s *SiteService) processReceivedSubmission(ctx context.Context, dbs database.DBSession, fileReadCloserProvider ReadCloserProvider, filename string, filesize int64, sid *int64, submissionLevel string, isDraft bool) (*string, []string, int64, error) {
 	uid := utils.UserID(ctx)
 	if uid == 0 {
 		utils.LogCtx(ctx).Panic("no user associated with request")
@@ -138,7 +138,7 @@ func (s *SiteService) processReceivedSubmission(ctx context.Context, dbs databas
 	isSubmissionNew := true
 
 	if sid == nil {
-		submissionID, err = s.dal.StoreSubmission(dbs, submissionLevel)
+		submissionID, err = s.dal.StoreSubmission(dbs, submissionLevel, isDraft)
 		if err != nil {
 			utils.LogCtx(ctx).Error(err)
 			return &destinationFilePath, nil, 0, dberr(err)
@@ -190,8 +190,11 @@ func (s *SiteService) processReceivedSubmission(ctx context.Context, dbs databas
 		SHA256Sum:        hex.EncodeToString(sha256sum.Sum(nil)),
 	}
 
-	fid, err := s.dal.StoreSubmissionFile(dbs, sf)
+	fid, err := s.dal.StoreSubmissionFileWithLimit(dbs, sf, s.maxFilesPerSubmission)
 	if err != nil {
+		if err.Error() == constants.ErrorSubmissionFileLimitReached {
+			return &destinationFilePath, nil, 0, perr(fmt.Sprintf("submission already has the maximum of %d files, delete an old file before uploading a new one", s.maxFilesPerSubmission), http.StatusUnprocessableEntity)
+		}
 		me, ok := err.(*mysql.MySQLError)
 		if ok {
 			if me.Number == 1062 {
@@ -324,7 +327,8 @@ func (s *SiteService) processReceivedSubmission(ctx context.Context, dbs databas
 		return &destinationFilePath, imageFilePaths, 0, dberr(err)
 	}
 
-	if err := s.dal.UpdateSubmissionCacheTable(dbs, submissionID); err != nil {
+	// the status follows the upload comment, unless the submission is already accepted
+	if err := s.dal.RecomputeSubmissionDerivedFields(dbs, submissionID); err != nil {
 		utils.LogCtx(ctx).Error(err)
 		return &destinationFilePath, imageFilePaths, 0, dberr(err)
 	}
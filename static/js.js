@@ -260,6 +260,13 @@ function deleteSubmission(sid) {
         "Please provide a reason to delete this submission and all its related data:")
 }
 
+function publishSubmission(sid) {
+    sendXHR(`/api/submission/${sid}/publish`, "POST", null, true,
+        "Failed to publish submission.",
+        "Submission published successfully.",
+        null)
+}
+
 function overrideBot(sid) {
     sendXHR(`/api/submission/${sid}/override`, "POST", null, true,
         "Failed to override bot decision.",
@@ -9,7 +9,11 @@ function initResumableUploader(target, maxFiles, allowedExtensions) {
         target: target,
         chunkSize: 16 * 1024 * 1024,
         simultaneousUploads: 2,
-        query: {},
+        query: function () {
+            // only present on the new submission form, uploads to an existing submission leave its draft state alone
+            let draft = document.getElementById("resumable-draft")
+            return draft !== null && draft.checked ? {draft: "true"} : {}
+        },
         generateUniqueIdentifier: function (file, event) {
             let relativePath = getFilename(file)
             let size = file.size
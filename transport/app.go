@@ -19,6 +19,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"reflect"
 	"sync"
 	"syscall"
 	"time"
@@ -44,6 +45,16 @@ func InitApp(l *logrus.Entry, conf *config.Config, db *sql.DB, authBotSession, n
 	decoder := schema.NewDecoder()
 	decoder.ZeroEmpty(false)
 	decoder.IgnoreUnknownKeys(true)
+	decoder.RegisterConverter(time.Time{}, func(s string) reflect.Value {
+		if len(s) == 0 {
+			return reflect.ValueOf(time.Time{})
+		}
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return reflect.Value{}
+		}
+		return reflect.ValueOf(t)
+	})
 
 	a := &App{
 		Conf: conf,
@@ -53,7 +64,8 @@ func InitApp(l *logrus.Entry, conf *config.Config, db *sql.DB, authBotSession, n
 		},
 		Service: service.New(l, db, authBotSession, notificationBotSession, conf.FlashpointServerID,
 			conf.NotificationChannelID, conf.CurationFeedChannelID, conf.ValidatorServerURL, conf.SessionExpirationSeconds,
-			constants.SubmissionsDir, constants.SubmissionImagesDir, conf.FlashfreezeDirFullPath, conf.IsDev, rsu, conf.ArchiveIndexerServerURL, conf.FlashfreezeIngestDirFullPath, conf.FixesDirFullPath),
+			constants.SubmissionsDir, constants.SubmissionImagesDir, conf.FlashfreezeDirFullPath, conf.IsDev, rsu, conf.ArchiveIndexerServerURL, conf.FlashfreezeIngestDirFullPath, conf.FixesDirFullPath,
+			time.Duration(conf.DBSlowQueryThresholdMs)*time.Millisecond, int(conf.MaxFilesPerSubmission)),
 		decoder:             decoder,
 		authMiddlewareCache: memoize.NewMemoizer(5*time.Second, 60*time.Minute),
 	}
@@ -80,6 +92,11 @@ func InitApp(l *logrus.Entry, conf *config.Config, db *sql.DB, authBotSession, n
 	wg.Add(1)
 	go memstatsPrinter(l, ctx, wg)
 
+	l.Infoln("starting the db stats printer...")
+
+	wg.Add(1)
+	go a.Service.RunDBStatsPrinter(l, ctx, wg)
+
 	term := make(chan os.Signal, 1)
 	signal.Notify(term, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 	<-term
@@ -100,6 +117,11 @@ func InitApp(l *logrus.Entry, conf *config.Config, db *sql.DB, authBotSession, n
 		l.WithError(err).Errorln("server shutdown failed")
 	}
 
+	l.Infoln("closing the site service...")
+	if err := a.Service.Close(); err != nil {
+		l.WithError(err).Errorln("site service close failed")
+	}
+
 	l.Infoln("goodbye")
 }
 
@@ -3,6 +3,7 @@ package transport
 import (
 	"fmt"
 	"github.com/Dri0m/flashpoint-submission-system/constants"
+	"github.com/Dri0m/flashpoint-submission-system/logging"
 	"github.com/Dri0m/flashpoint-submission-system/utils"
 	"github.com/gorilla/mux"
 	"net/http"
@@ -39,6 +40,8 @@ func (a *App) HandleDownloadSubmissionFile(w http.ResponseWriter, r *http.Reques
 	}
 	defer f.Close()
 
+	a.Service.RecordFileDownloads(ctx, []int64{sfid}, logging.RequestGetRemoteAddress(r))
+
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", sf.CurrentFilename))
 	w.Header().Set("Content-Type", "application/octet-stream")
 	http.ServeContent(w, r, sf.CurrentFilename, sf.UploadedAt, f)
@@ -72,6 +75,8 @@ func (a *App) HandleDownloadSubmissionBatch(w http.ResponseWriter, r *http.Reque
 		filePaths = append(filePaths, fmt.Sprintf("%s/%s", constants.SubmissionsDir, sf.CurrentFilename))
 	}
 
+	a.Service.RecordFileDownloads(ctx, sfids, logging.RequestGetRemoteAddress(r))
+
 	filename := fmt.Sprintf("fpfss-batch-%dfiles-%s.tar", len(sfs), utils.NewRealRandomStringProvider().RandomString(16))
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 	w.Header().Set("Content-Type", "application/octet-stream")
@@ -78,6 +78,8 @@ func (a *App) HandleCommentReceiverBatch(w http.ResponseWriter, r *http.Request)
 	// TODO use gorilla/schema
 	formAction := r.FormValue("action")
 	formMessage := r.FormValue("message")
+	formFormat := r.FormValue("format")
+	formVisibility := r.FormValue("visibility")
 	formIgnoreDupeActions := r.FormValue("ignore-duplicate-actions")
 
 	if len([]rune(formMessage)) > 20000 {
@@ -87,7 +89,7 @@ func (a *App) HandleCommentReceiverBatch(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := a.Service.ReceiveComments(ctx, uid, sids, formAction, formMessage, formIgnoreDupeActions); err != nil {
+	if err := a.Service.ReceiveComments(ctx, uid, sids, formAction, formMessage, formFormat, formVisibility, formIgnoreDupeActions); err != nil {
 		writeError(ctx, w, err)
 		return
 	}
@@ -188,6 +190,71 @@ func (a *App) HandleSoftDeleteComment(w http.ResponseWriter, r *http.Request) {
 	writeResponse(ctx, w, nil, http.StatusNoContent)
 }
 
+func (a *App) HandlePinComment(w http.ResponseWriter, r *http.Request) {
+	a.handleSetCommentPinned(w, r, true)
+}
+
+func (a *App) HandleUnpinComment(w http.ResponseWriter, r *http.Request) {
+	a.handleSetCommentPinned(w, r, false)
+}
+
+func (a *App) handleSetCommentPinned(w http.ResponseWriter, r *http.Request, pinned bool) {
+	ctx := r.Context()
+	params := mux.Vars(r)
+	submissionID := params[constants.ResourceKeySubmissionID]
+	commentID := params[constants.ResourceKeyCommentID]
+
+	sid, err := strconv.ParseInt(submissionID, 10, 64)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, perr("invalid submission id", http.StatusBadRequest))
+		return
+	}
+
+	cid, err := strconv.ParseInt(commentID, 10, 64)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, perr("invalid comment id", http.StatusBadRequest))
+		return
+	}
+
+	if err := a.Service.SetCommentPinned(ctx, sid, cid, pinned); err != nil {
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, nil, http.StatusNoContent)
+}
+
+func (a *App) HandleAddCommentReaction(w http.ResponseWriter, r *http.Request) {
+	a.handleSetCommentReaction(w, r, true)
+}
+
+func (a *App) HandleRemoveCommentReaction(w http.ResponseWriter, r *http.Request) {
+	a.handleSetCommentReaction(w, r, false)
+}
+
+func (a *App) handleSetCommentReaction(w http.ResponseWriter, r *http.Request, react bool) {
+	ctx := r.Context()
+	params := mux.Vars(r)
+	commentID := params[constants.ResourceKeyCommentID]
+	reaction := params[constants.ResourceKeyCommentReaction]
+
+	cid, err := strconv.ParseInt(commentID, 10, 64)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, perr("invalid comment id", http.StatusBadRequest))
+		return
+	}
+
+	if err := a.Service.SetCommentReaction(ctx, cid, reaction, react); err != nil {
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, nil, http.StatusNoContent)
+}
+
 func (a *App) HandleOverrideBot(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -209,6 +276,27 @@ func (a *App) HandleOverrideBot(w http.ResponseWriter, r *http.Request) {
 	writeResponse(ctx, w, nil, http.StatusNoContent)
 }
 
+func (a *App) HandlePublishSubmission(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	submissionID := params[constants.ResourceKeySubmissionID]
+
+	sid, err := strconv.ParseInt(submissionID, 10, 64)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, perr("invalid submission id", http.StatusBadRequest))
+		return
+	}
+
+	if err := a.Service.PublishSubmission(ctx, sid); err != nil {
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, nil, http.StatusNoContent)
+}
+
 func (a *App) HandleSubmissionReceiverResumable(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	params := mux.Vars(r)
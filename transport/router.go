@@ -408,6 +408,42 @@ func (a *App) handleRequests(l *logrus.Entry, srv *http.Server, router *mux.Rout
 			a.HandleSoftDeleteComment, muxAll(isDeleter))))).
 		Methods("DELETE")
 
+	// comment pinning
+
+	router.Handle(
+		fmt.Sprintf("/api/submission/{%s}/comment/{%s}/pin", constants.ResourceKeySubmissionID, constants.ResourceKeyCommentID),
+		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(
+			a.HandlePinComment, muxAny(isStaff))))).
+		Methods("POST")
+
+	router.Handle(
+		fmt.Sprintf("/api/submission/{%s}/comment/{%s}/pin", constants.ResourceKeySubmissionID, constants.ResourceKeyCommentID),
+		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(
+			a.HandleUnpinComment, muxAny(isStaff))))).
+		Methods("DELETE")
+
+	// comment reactions
+
+	router.Handle(
+		fmt.Sprintf("/api/submission/{%s}/comment/{%s}/reaction/{%s}", constants.ResourceKeySubmissionID, constants.ResourceKeyCommentID, constants.ResourceKeyCommentReaction),
+		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(
+			a.HandleAddCommentReaction, muxAny(isStaff))))).
+		Methods("POST")
+
+	router.Handle(
+		fmt.Sprintf("/api/submission/{%s}/comment/{%s}/reaction/{%s}", constants.ResourceKeySubmissionID, constants.ResourceKeyCommentID, constants.ResourceKeyCommentReaction),
+		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(
+			a.HandleRemoveCommentReaction, muxAny(isStaff))))).
+		Methods("DELETE")
+
+	// draft publishing
+
+	router.Handle(
+		fmt.Sprintf("/api/submission/{%s}/publish", constants.ResourceKeySubmissionID),
+		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(
+			a.HandlePublishSubmission, muxAny(userOwnsSubmission))))).
+		Methods("POST")
+
 	// bot override
 
 	router.Handle(
@@ -1,14 +1,15 @@
 package types
 
 type DiscordUser struct {
-	ID            int64  `json:"id"`
-	Username      string `json:"username"`
-	Avatar        string `json:"avatar"`
-	Discriminator string `json:"discriminator"`
-	PublicFlags   int64  `json:"public_flags"`
-	Flags         int64  `json:"flags"`
-	Locale        string `json:"locale"`
-	MFAEnabled    bool   `json:"mfa_enabled"`
+	ID            int64   `json:"id"`
+	Username      string  `json:"username"`
+	Avatar        string  `json:"avatar"`
+	Discriminator string  `json:"discriminator"`
+	PublicFlags   int64   `json:"public_flags"`
+	Flags         int64   `json:"flags"`
+	Locale        string  `json:"locale"`
+	MFAEnabled    bool    `json:"mfa_enabled"`
+	DisplayName   *string `json:"-"` // set in this system, preferred over username where shown
 }
 
 type DiscordRole struct {
@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"github.com/Dri0m/flashpoint-submission-system/constants"
 	"reflect"
 	"time"
 )
@@ -31,9 +32,53 @@ type CurationMeta struct {
 	Version             *string `json:"Version"`
 	CurationNotes       *string `json:"Curation Notes"`
 	MountParameters     *string `json:"Mount Parameters"`
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
 	//AdditionalApplications *CurationFormatAddApps `json:"Additional Applications"`
 }
 
+type CurationMetaFieldDiff struct {
+	Field string  `json:"field"` // JSON name of the curation meta field
+	Old   *string `json:"old"`
+	New   *string `json:"new"`
+}
+
+type CurationMetaDiff struct {
+	OldSubmissionFileID int64                    `json:"old_submission_file_id"`
+	NewSubmissionFileID int64                    `json:"new_submission_file_id"`
+	Fields              []*CurationMetaFieldDiff `json:"fields"`
+}
+
+// DiffCurationMetas returns fields which differ between two curation metas, a nil meta has all fields empty
+func DiffCurationMetas(oldMeta, newMeta *CurationMeta) []*CurationMetaFieldDiff {
+	if oldMeta == nil {
+		oldMeta = &CurationMeta{}
+	}
+	if newMeta == nil {
+		newMeta = &CurationMeta{}
+	}
+
+	result := make([]*CurationMetaFieldDiff, 0)
+
+	ov := reflect.ValueOf(oldMeta).Elem()
+	nv := reflect.ValueOf(newMeta).Elem()
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("json")
+		if name == "" {
+			continue // not a curation field
+		}
+		o := ov.Field(i).Interface().(*string)
+		n := nv.Field(i).Interface().(*string)
+		if o == nil && n == nil || o != nil && n != nil && *o == *n {
+			continue
+		}
+		result = append(result, &CurationMetaFieldDiff{Field: name, Old: o, New: n})
+	}
+
+	return result
+}
+
 type MasterDatabaseGame struct {
 	UUID                string
 	Title               *string
@@ -59,11 +104,14 @@ type MasterDatabaseGame struct {
 }
 
 type Comment struct {
-	AuthorID     int64
-	SubmissionID int64
-	Action       string
-	Message      *string
-	CreatedAt    time.Time
+	AuthorID        int64
+	SubmissionID    int64
+	Action          string
+	Message         *string
+	Format          string // plain or markdown, plain when empty
+	Visibility      string // public or internal, public when empty
+	CreatedAt       time.Time
+	ParentCommentID *int64
 }
 
 type SubmissionFile struct {
@@ -97,6 +145,9 @@ type ExtendedSubmission struct {
 	SubmitterID                 int64     // oldest file
 	SubmitterUsername           string    // oldest file
 	SubmitterAvatarURL          string    // oldest file
+	OwnerID                     int64     // submitter, unless ownership was handed over
+	IsDraft                     bool      // hidden from reviewers until published by the owner
+	Status                      string    // review status, see constants.GetAllowedSubmissionStatuses
 	UpdaterID                   int64     // newest file
 	UpdaterUsername             string    // newest file
 	UpdaterAvatarURL            string    // newest file
@@ -121,49 +172,74 @@ type ExtendedSubmission struct {
 	ApprovedUserIDs             []int64
 	VerifiedUserIDs             []int64
 	DistinctActions             []string
+	AcceptedGameID              *string // game in the main flashpoint database the submission was exported as
+	AcceptedAt                  *time.Time
+	ViewCount                   int64 // distinct viewers per day, summed over all days
+	ReviewDeadline              *time.Time
+	Priority                    int64     // higher goes first when ordering by priority
+	LastActivityAt              time.Time // newest file or comment, whichever is later
+	IsExtreme                   bool      // newest file
 }
 
 type SubmissionsFilter struct {
-	SubmissionIDs                  []int64  `schema:"submission-id"`
-	SubmitterID                    *int64   `schema:"submitter-id"`
-	TitlePartial                   *string  `schema:"title-partial"`
-	SubmitterUsernamePartial       *string  `schema:"submitter-username-partial"`
-	PlatformPartial                *string  `schema:"platform-partial"`
-	LibraryPartial                 *string  `schema:"library-partial"`
-	OriginalFilenamePartialAny     *string  `schema:"original-filename-partial-any"`
-	CurrentFilenamePartialAny      *string  `schema:"current-filename-partial-any"`
-	MD5SumPartialAny               *string  `schema:"md5sum-partial-any"`
-	SHA256SumPartialAny            *string  `schema:"sha256sum-partial-any"`
-	BotActions                     []string `schema:"bot-action"`
-	ActionsAfterMyLastComment      []string `schema:"post-last-action"`
-	ResultsPerPage                 *int64   `schema:"results-per-page"`
-	Page                           *int64   `schema:"page"`
-	AssignedStatusTesting          *string  `schema:"assigned-status-testing"`
-	AssignedStatusVerification     *string  `schema:"assigned-status-verification"`
-	RequestedChangedStatus         *string  `schema:"requested-changes-status"`
-	ApprovalsStatus                *string  `schema:"approvals-status"`
-	VerificationStatus             *string  `schema:"verification-status"`
-	SubmissionLevels               []string `schema:"sumbission-level"`
-	AssignedStatusTestingMe        *string  `schema:"assigned-status-testing-me"`
-	AssignedStatusVerificationMe   *string  `schema:"assigned-status-verification-me"`
-	RequestedChangedStatusMe       *string  `schema:"requested-changes-status-me"`
-	ApprovalsStatusMe              *string  `schema:"approvals-status-me"`
-	VerificationStatusMe           *string  `schema:"verification-status-me"`
-	AssignedStatusUserID           *int64   `schema:"assigned-status-user-id"`
-	AssignedStatusTestingUser      *string  `schema:"assigned-status-testing-user"`
-	AssignedStatusVerificationUser *string  `schema:"assigned-status-verification-user"`
-	RequestedChangedStatusUser     *string  `schema:"requested-changes-status-user"`
-	ApprovalsStatusUser            *string  `schema:"approvals-status-user"`
-	VerificationStatusUser         *string  `schema:"verification-status-user"`
-	IsExtreme                      *string  `schema:"is-extreme"`
-	DistinctActions                []string `schema:"distinct-action"`
-	DistinctActionsNot             []string `schema:"distinct-action-not"`
-	LaunchCommandFuzzy             *string  `schema:"launch-command-fuzzy"`
-	LastUploaderNotMe              *string  `schema:"last-uploader-not-me"`
-	OrderBy                        *string  `schema:"order-by"`
-	AscDesc                        *string  `schema:"asc-desc"`
-	SubscribedMe                   *string  `schema:"subscribed-me"`
+	SubmissionIDs                  []int64    `schema:"submission-id"`
+	SubmitterID                    *int64     `schema:"submitter-id"`
+	SubmitterIDs                   []int64    `schema:"submitter-ids"` // combined with SubmitterID using AND, empty means any submitter
+	TitlePartial                   *string    `schema:"title-partial"`
+	SubmitterUsernamePartial       *string    `schema:"submitter-username-partial"`
+	PlatformPartial                *string    `schema:"platform-partial"`
+	LibraryPartial                 *string    `schema:"library-partial"`
+	OriginalFilenamePartialAny     *string    `schema:"original-filename-partial-any"`
+	CurrentFilenamePartialAny      *string    `schema:"current-filename-partial-any"`
+	MD5SumPartialAny               *string    `schema:"md5sum-partial-any"`
+	SHA256SumPartialAny            *string    `schema:"sha256sum-partial-any"`
+	BotActions                     []string   `schema:"bot-action"`
+	Status                         []string   `schema:"status"`
+	ActionsAfterMyLastComment      []string   `schema:"post-last-action"`
+	ResultsPerPage                 *int64     `schema:"results-per-page"`
+	Page                           *int64     `schema:"page"`
+	AssignedStatusTesting          *string    `schema:"assigned-status-testing"`
+	AssignedStatusVerification     *string    `schema:"assigned-status-verification"`
+	RequestedChangedStatus         *string    `schema:"requested-changes-status"`
+	ApprovalsStatus                *string    `schema:"approvals-status"`
+	VerificationStatus             *string    `schema:"verification-status"`
+	SubmissionLevels               []string   `schema:"sumbission-level"`
+	AssignedStatusTestingMe        *string    `schema:"assigned-status-testing-me"`
+	AssignedStatusVerificationMe   *string    `schema:"assigned-status-verification-me"`
+	RequestedChangedStatusMe       *string    `schema:"requested-changes-status-me"`
+	ApprovalsStatusMe              *string    `schema:"approvals-status-me"`
+	VerificationStatusMe           *string    `schema:"verification-status-me"`
+	AssignedStatusUserID           *int64     `schema:"assigned-status-user-id"`
+	AssignedStatusTestingUser      *string    `schema:"assigned-status-testing-user"`
+	AssignedStatusVerificationUser *string    `schema:"assigned-status-verification-user"`
+	RequestedChangedStatusUser     *string    `schema:"requested-changes-status-user"`
+	ApprovalsStatusUser            *string    `schema:"approvals-status-user"`
+	VerificationStatusUser         *string    `schema:"verification-status-user"`
+	IsExtreme                      *string    `schema:"is-extreme"`
+	DistinctActions                []string   `schema:"distinct-action"`
+	DistinctActionsNot             []string   `schema:"distinct-action-not"`
+	LaunchCommandFuzzy             *string    `schema:"launch-command-fuzzy"`
+	LastUploaderNotMe              *string    `schema:"last-uploader-not-me"`
+	OrderBy                        *string    `schema:"order-by"`
+	AscDesc                        *string    `schema:"asc-desc"`
+	SubscribedMe                   *string    `schema:"subscribed-me"`
+	Tag                            *string    `schema:"tag"`
+	Language                       *string    `schema:"language"`
+	CurationStatus                 *string    `schema:"curation-status"`    // status field of the curation meta, not the review status
+	AfterUpdatedAt                 *int64     `schema:"after-updated-at"`   // keyset pagination, only with the default ordering
+	AfterID                        *int64     `schema:"after-id"`           // keyset pagination, only with the default ordering
+	LastActionBefore               *time.Time `schema:"last-action-before"` // submissions without any action count as stale
+	LastActionAfter                *time.Time `schema:"last-action-after"`
+	DeadlineBefore                 *time.Time `schema:"deadline-before"`
+	Overdue                        *bool      `schema:"overdue"` // deadline passed without an active approval
+	MinPriority                    *int64     `schema:"min-priority"`
+	AcceptedStatus                 *string    `schema:"accepted-status"`
+	CommentedByUID                 *int64     `schema:"commented-by-uid"`
+	NotCommentedByUID              *int64     `schema:"not-commented-by-uid"`
 	ExcludeLegacy                  bool
+	ExcludeExtreme                 bool // set by the server for viewers who have not opted in to extreme content
+	SubmitterDeauthorized          bool // submitter holds no discord roles anymore
+	MetaComplete                   bool // newest file has title, platform, library and launch command filled in
 }
 
 func unzeroNilPointers(x interface{}) {
@@ -179,6 +255,11 @@ func unzeroNilPointers(x interface{}) {
 			if e.Kind() == reflect.String && e.String() == "" {
 				f.Set(reflect.Zero(f.Type()))
 			}
+			if e.Kind() == reflect.Struct {
+				if t, ok := e.Interface().(time.Time); ok && t.IsZero() {
+					f.Set(reflect.Zero(f.Type()))
+				}
+			}
 		}
 	}
 }
@@ -200,6 +281,11 @@ func (sf *SubmissionsFilter) Validate() error {
 			return fmt.Errorf("submitter id must be >= 1")
 		}
 	}
+	for _, uid := range sf.SubmitterIDs {
+		if uid < 1 {
+			return fmt.Errorf("submitter id must be >= 1")
+		}
+	}
 	if sf.ResultsPerPage != nil && *sf.ResultsPerPage < 1 {
 		if *sf.ResultsPerPage == 0 {
 			sf.ResultsPerPage = nil
@@ -215,6 +301,22 @@ func (sf *SubmissionsFilter) Validate() error {
 		}
 	}
 
+	for _, status := range sf.Status {
+		allowed := false
+		for _, s := range constants.GetAllowedSubmissionStatuses() {
+			if status == s {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("invalid status")
+		}
+	}
+	if sf.AcceptedStatus != nil && *sf.AcceptedStatus != "accepted" && *sf.AcceptedStatus != "unaccepted" {
+		return fmt.Errorf("invalid accepted-status")
+	}
+
 	if sf.AssignedStatusTesting != nil && *sf.AssignedStatusTesting != "unassigned" && *sf.AssignedStatusTesting != "assigned" {
 		return fmt.Errorf("invalid assigned-status-testing")
 	}
@@ -283,7 +385,7 @@ func (sf *SubmissionsFilter) Validate() error {
 	if sf.LastUploaderNotMe != nil && *sf.LastUploaderNotMe != "yes" {
 		return fmt.Errorf("last-uploader-not-me")
 	}
-	if sf.OrderBy != nil && *sf.OrderBy != "uploaded" && *sf.OrderBy != "updated" && *sf.OrderBy != "size" {
+	if sf.OrderBy != nil && *sf.OrderBy != "uploaded" && *sf.OrderBy != "updated" && *sf.OrderBy != "size" && *sf.OrderBy != "activity" && *sf.OrderBy != "priority" && *sf.OrderBy != "random" {
 		return fmt.Errorf("invalid order-by")
 	}
 	if sf.AscDesc != nil && *sf.AscDesc != "asc" && *sf.AscDesc != "desc" {
@@ -292,18 +394,52 @@ func (sf *SubmissionsFilter) Validate() error {
 	if sf.SubscribedMe != nil && *sf.SubscribedMe != "no" && *sf.SubscribedMe != "yes" {
 		return fmt.Errorf("invalid subscribed-me")
 	}
+	if (sf.AfterUpdatedAt == nil) != (sf.AfterID == nil) {
+		return fmt.Errorf("after-updated-at and after-id must be set together")
+	}
+	if sf.AfterUpdatedAt != nil && ((sf.OrderBy != nil && *sf.OrderBy != "updated") || (sf.AscDesc != nil && *sf.AscDesc != "desc")) {
+		return fmt.Errorf("after-updated-at and after-id can only be used with the default ordering")
+	}
+	if sf.LastActionBefore != nil && sf.LastActionAfter != nil && !sf.LastActionAfter.Before(*sf.LastActionBefore) {
+		return fmt.Errorf("last-action-after must be before last-action-before")
+	}
 	return nil
 }
 
+// NextPageCursor returns keyset pagination values pointing after the last submission of a given page
+func NextPageCursor(page []*ExtendedSubmission) (afterUpdatedAt, afterID *int64) {
+	if len(page) == 0 {
+		return nil, nil
+	}
+	last := page[len(page)-1]
+	updatedAt := last.UpdatedAt.Unix()
+	id := last.SubmissionID
+	return &updatedAt, &id
+}
+
 type ExtendedComment struct {
-	CommentID    int64
-	AuthorID     int64
-	Username     string
-	AvatarURL    string
-	SubmissionID int64
-	Action       string
-	Message      *string
-	CreatedAt    time.Time
+	CommentID       int64
+	AuthorID        int64
+	Username        string
+	AvatarURL       string
+	SubmissionID    int64
+	Action          string
+	Message         *string
+	Format          string // markdown messages are meant to be rendered, plain messages are split on newlines
+	Visibility      string // internal comments are shown to staff only
+	CreatedAt       time.Time
+	Pinned          bool
+	ParentCommentID *int64
+	SubmissionTitle *string        // newest file, only filled for comments listed across submissions
+	Reactions       map[string]int // reaction counts by reaction type
+	ViewerReactions []string       // reactions of the user viewing the comment
+}
+
+// CommentRate is the number of comments a user posted within a trailing window
+type CommentRate struct {
+	UserID   int64
+	Username string
+	Count    int
 }
 
 type UpdateNotificationSettings struct {
@@ -352,6 +488,7 @@ type Notification struct {
 	Message   string
 	CreatedAt time.Time
 	SentAt    time.Time
+	Attempts  int64
 }
 
 type CurationImage struct {
@@ -408,6 +545,7 @@ type ResumableParams struct {
 	ResumableRelativePath     string `schema:"resumableRelativePath"`
 	ResumableCurrentChunkSize int64  `schema:"resumableCurrentChunkSize"`
 	ResumableTotalChunks      int    `schema:"resumableTotalChunks"`
+	Draft                     bool   `schema:"draft"` // only applies when the upload creates a new submission
 }
 
 type FlashfreezeFile struct {
@@ -620,6 +758,15 @@ func (ff *FixesFilter) Validate() error {
 	return nil
 }
 
+type SubmissionExport struct {
+	Submission     *ExtendedSubmission
+	Files          []*ExtendedSubmissionFile
+	CurationMetas  []*CurationMeta
+	CurationImages []*CurationImage
+	Comments       []*ExtendedComment
+	Meta           map[string]string
+}
+
 type ExtendedFixesItem struct {
 	FixID             int64
 	Title             string
@@ -628,3 +775,157 @@ type ExtendedFixesItem struct {
 	SubmitterUsername string
 	UploadedAt        *time.Time
 }
+
+type SessionSummary struct {
+	ActiveSessions     int64
+	DistinctUsers      int64
+	OldestCreatedAt    *time.Time // nil if no active session has a known creation time
+	UnknownAgeSessions int64      // active sessions which predate recording of creation time
+	TopUsers           []*SessionUserCount
+}
+
+type SessionUserCount struct {
+	UserID   int64
+	Username string
+	Count    int64
+}
+
+type SubmitterCount struct {
+	UserID    int64
+	Username  string
+	AvatarURL string
+	Count     int64
+}
+
+type SubmissionMetaConflict struct {
+	Submission        *ExtendedSubmission
+	ConflictingFields []string // curation_meta columns which differ between files of the submission
+}
+
+type StatusTransition struct {
+	ID            int64
+	SubmissionID  int64
+	FromStatus    string
+	ToStatus      string
+	ActorID       int64
+	ActorUsername string
+	CreatedAt     time.Time
+}
+
+type DailyViewCount struct {
+	Date    time.Time
+	Viewers int64
+}
+
+type ReviewerNote struct {
+	ID           int64
+	SubmissionID int64
+	AuthorID     int64
+	Username     string
+	Body         string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type FileDownload struct {
+	ID               int64
+	SubmissionFileID int64
+	UserID           int64
+	Username         string
+	IP               string
+	DownloadedAt     time.Time
+}
+
+type UserAnonymization struct {
+	ReassignedRows map[string]int64 // rows handed over to the anonymized user, by table and column
+	DeletedRows    map[string]int64 // personal rows removed, by table
+	SubmissionIDs  []int64          // submissions whose cache was recomputed
+}
+
+type SubmissionMerge struct {
+	FilesMoved             int64
+	CommentsMoved          int64
+	SubscriptionsMoved     int64 // subscribers of the kept submission are not counted
+	NotesMoved             int64 // reviewers with a note on the kept submission are not counted
+	StatusTransitionsMoved int64
+	MetaMoved              int64 // keys the kept submission already has are not counted
+}
+
+type UserDeauthorization struct {
+	RolesRemoved           int64
+	SessionsRevoked        int64
+	UnassignedTesting      int64   // testing assignments released
+	UnassignedVerification int64   // verification assignments released
+	WatchesRemoved         int64   // always zero unless watches were asked to be removed
+	SubmissionIDs          []int64 // submissions the user was released from
+}
+
+type ArchivedSubmission struct {
+	ID                int64
+	SubmitterID       *int64
+	SubmitterUsername *string
+	Title             *string // of the newest file, nil when the file has no meta
+	Platform          *string
+	AcceptedGameID    *string
+	AcceptedAt        *time.Time
+}
+
+type SubmissionDetail struct {
+	Submission     *ExtendedSubmission
+	CurationMeta   *CurationMeta // of the newest file, nil when the file has no meta
+	CurationImages []*CurationImage
+	Comments       []*ExtendedComment
+}
+
+type BulkAssignmentResult struct {
+	NewlyAssigned   int // submissions nobody was assigned to
+	Reassigned      int // submissions taken over from other testers
+	AlreadyAssigned int // submissions the assignee already had, left untouched
+}
+
+type QueueReport struct {
+	GeneratedAt         time.Time
+	PendingCount        int64
+	ByStatus            map[string]int64
+	ByPlatform          map[string]int64 // platform of the newest file, empty when unknown
+	ByAssignee          map[int64]int64  // user ID to number of submissions assigned for testing or verification
+	OldestUnreviewedAge *time.Duration   // nil when no submission awaits its first review
+	PendingSize         int64            // bytes of all files of pending submissions
+}
+
+type LatencyPercentiles struct {
+	Count int // submissions which reached the stage within the window
+	P50   time.Duration
+	P90   time.Duration
+}
+
+type LatencyStats struct {
+	Since       time.Time
+	Until       time.Time
+	FirstReview LatencyPercentiles // from the first upload to the first human action other than an upload
+	Acceptance  LatencyPercentiles // from the first upload to acceptance
+}
+
+type TimeBucketCount struct {
+	Start time.Time // in UTC, weeks start on monday
+	Count int64
+}
+
+type ActionUsage struct {
+	BotCount   int64
+	HumanCount int64
+}
+
+type CommentTreeNode struct {
+	Comment *ExtendedComment
+	Replies []*CommentTreeNode
+}
+
+type ActionEvent struct {
+	CommentID int64
+	Action    string
+	ActorID   int64
+	Username  string
+	IsBot     bool
+	CreatedAt time.Time
+}
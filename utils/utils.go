@@ -10,10 +10,12 @@ import (
 	"math/rand"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"runtime"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // https://stackoverflow.com/a/31832326
@@ -64,6 +66,65 @@ func FormatLike(s string) string {
 	return "%" + s + "%"
 }
 
+// EscapeLike escapes LIKE wildcards so that s is matched literally, assuming the default backslash escape character
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// SplitCurationTags splits semicolon-separated curation tags, trimming whitespace and dropping empty entries
+func SplitCurationTags(tags string) []string {
+	result := make([]string, 0)
+	for _, tag := range strings.Split(tags, ";") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) == 0 {
+			continue
+		}
+		result = append(result, tag)
+	}
+	return result
+}
+
+// NormalizeTitle lowercases a title and strips punctuation and whitespace, mirroring curation_meta.normalized_title
+func NormalizeTitle(title string) string {
+	sb := strings.Builder{}
+	sb.Grow(len(title))
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsPunct(r) || unicode.IsSpace(r) {
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// NormalizeSourceURL lowercases the scheme and host of a URL and strips its query and fragment, mirroring curation_meta.normalized_source.
+// Strings that are not absolute URLs are returned trimmed but otherwise unchanged.
+func NormalizeSourceURL(source string) string {
+	source = strings.TrimSpace(source)
+
+	u, err := url.Parse(source)
+	if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+		return source
+	}
+
+	schemeEnd := strings.Index(source, "://")
+	if schemeEnd == -1 {
+		return source
+	}
+	hostStart := schemeEnd + len("://")
+	hostEnd := len(source)
+	if i := strings.IndexAny(source[hostStart:], "/?#"); i != -1 {
+		hostEnd = hostStart + i
+	}
+
+	rest := source[hostEnd:]
+	if i := strings.IndexAny(rest, "?#"); i != -1 {
+		rest = rest[:i]
+	}
+
+	return strings.ToLower(source[:hostEnd]) + rest
+}
+
 func WriteTarball(w io.Writer, filePaths []string) error {
 	tarWriter := tar.NewWriter(w)
 	defer tarWriter.Close()